@@ -0,0 +1,267 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultArchiveFileSize = 10 << 20
+	DefaultArchiveFileAge  = 24 * time.Hour
+	archivePrefix          = "jobs-"
+	archiveSuffix          = ".jsonl.gz"
+	archiveTimeFormat      = "20060102T150405.000000000Z"
+)
+
+type archiveOptions struct {
+	fileSize int64
+	fileAge  time.Duration
+	maxSize  int64
+	maxAge   time.Duration
+	clock    Clock
+}
+
+type ArchiveOption func(*archiveOptions)
+
+// WithArchiveRotation sets the size in bytes and age at which a new archive file is started,
+// defaults to DefaultArchiveFileSize and DefaultArchiveFileAge.
+func WithArchiveRotation(size int64, age time.Duration) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.fileSize = size
+		o.fileAge = age
+	}
+}
+
+// WithArchiveRetention sets the total size in bytes and the age after which the oldest archive
+// files are removed. Zero values disable the respective limit, which is the default.
+func WithArchiveRetention(size int64, age time.Duration) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.maxSize = size
+		o.maxAge = age
+	}
+}
+
+// WithArchiveClock sets the clock used for file rotation and retention.
+func WithArchiveClock(c Clock) ArchiveOption {
+	return func(o *archiveOptions) {
+		o.clock = c
+	}
+}
+
+// Archiver appends jobs to rotating gzip compressed JSON lines files in a directory. Each append
+// adds a gzip member to the current file, files are named after the time they were started.
+type Archiver struct {
+	dir string
+	opt archiveOptions
+	mu  sync.Mutex
+}
+
+// ArchiveQuery selects archived jobs by ID and jobs created after Since and before Until, zero values match all jobs.
+type ArchiveQuery struct {
+	ID    string
+	Since time.Time
+	Until time.Time
+}
+
+type archiveFile struct {
+	name    string
+	started time.Time
+	size    int64
+}
+
+func NewArchiver(dir string, opts ...ArchiveOption) (*Archiver, error) {
+	o := archiveOptions{
+		fileSize: DefaultArchiveFileSize,
+		fileAge:  DefaultArchiveFileAge,
+		clock:    systemClock{},
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if err := os.MkdirAll(dir, 0o770); err != nil {
+		return nil, err
+	}
+	return &Archiver{dir: dir, opt: o}, nil
+}
+
+// Append writes the jobs to the current archive file and applies the retention limits.
+func (a *Archiver) Append(jobs []lib.Job) error {
+	if len(jobs) == 0 {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	files, err := a.files()
+	if err != nil {
+		return err
+	}
+	tNow := a.opt.clock.Now().UTC()
+	var cur archiveFile
+	if len(files) > 0 {
+		cur = files[len(files)-1]
+	}
+	if cur.name == "" || (a.opt.fileSize > 0 && cur.size >= a.opt.fileSize) || (a.opt.fileAge > 0 && tNow.Sub(cur.started) >= a.opt.fileAge) {
+		cur = archiveFile{name: archivePrefix + tNow.Format(archiveTimeFormat) + archiveSuffix, started: tNow}
+		files = append(files, cur)
+	}
+	if err = a.write(cur.name, jobs); err != nil {
+		return err
+	}
+	if fi, err := os.Stat(filepath.Join(a.dir, cur.name)); err == nil {
+		files[len(files)-1].size = fi.Size()
+	}
+	return a.cleanup(files, tNow)
+}
+
+func (a *Archiver) write(name string, jobs []lib.Job) error {
+	file, err := os.OpenFile(filepath.Join(a.dir, name), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o660)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	zw := gzip.NewWriter(file)
+	enc := json.NewEncoder(zw)
+	for _, j := range jobs {
+		if err = enc.Encode(j); err != nil {
+			return err
+		}
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// cleanup removes the oldest files exceeding the retention limits, the current file is kept. A file
+// counts as old as the time the next file was started.
+func (a *Archiver) cleanup(files []archiveFile, tNow time.Time) error {
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	var errs []error
+	for i := 0; i < len(files)-1; i++ {
+		expired := a.opt.maxAge > 0 && tNow.Sub(files[i+1].started) >= a.opt.maxAge
+		if !expired && (a.opt.maxSize <= 0 || total <= a.opt.maxSize) {
+			break
+		}
+		if err := os.Remove(filepath.Join(a.dir, files[i].name)); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		total -= files[i].size
+	}
+	return errors.Join(errs...)
+}
+
+// files returns the archive files ordered by start time.
+func (a *Archiver) files() ([]archiveFile, error) {
+	entries, err := os.ReadDir(a.dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []archiveFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, archivePrefix) || !strings.HasSuffix(name, archiveSuffix) {
+			continue
+		}
+		started, err := time.Parse(archiveTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, archivePrefix), archiveSuffix))
+		if err != nil {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, archiveFile{name: name, started: started, size: fi.Size()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].started.Before(files[j].started)
+	})
+	return files, nil
+}
+
+// Search reads all archive files and returns the jobs matching the query ordered by creation time.
+func (a *Archiver) Search(ctx context.Context, query ArchiveQuery) ([]lib.Job, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	files, err := a.files()
+	if err != nil {
+		return nil, err
+	}
+	var jobs []lib.Job
+	for _, f := range files {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		if jobs, err = a.search(f.name, query, jobs); err != nil {
+			return nil, fmt.Errorf("reading archive file '%s' failed: %s", f.name, err)
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].Created.Before(jobs[j].Created)
+	})
+	return jobs, nil
+}
+
+func (a *Archiver) search(name string, query ArchiveQuery, jobs []lib.Job) ([]lib.Job, error) {
+	file, err := os.Open(filepath.Join(a.dir, name))
+	if err != nil {
+		return jobs, err
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return jobs, err
+	}
+	defer zr.Close()
+	dec := json.NewDecoder(zr)
+	for {
+		var j lib.Job
+		if err = dec.Decode(&j); err != nil {
+			if errors.Is(err, io.EOF) {
+				return jobs, nil
+			}
+			return jobs, err
+		}
+		if query.ID != "" && j.ID != query.ID {
+			continue
+		}
+		if !query.Since.IsZero() && !j.Created.After(query.Since) {
+			continue
+		}
+		if !query.Until.IsZero() && !j.Created.Before(query.Until) {
+			continue
+		}
+		jobs = append(jobs, j)
+	}
+}
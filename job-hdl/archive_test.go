@@ -0,0 +1,167 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"context"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func archivedIDs(t *testing.T, a *job_hdl.Archiver, query job_hdl.ArchiveQuery) []string {
+	t.Helper()
+	jobs, err := a.Search(context.Background(), query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	return ids
+}
+
+func TestArchiver(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 4; i++ {
+		id, err := hdl.Create(ctx, "test", jhtest.Result(i, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		clock.Advance(time.Minute)
+	}
+	exec.RunAll()
+	clock.Advance(time.Hour)
+	purged, err := hdl.Purge(ctx, 62*time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(purged) != 3 || purged[0].ID != ids[0] {
+		t.Fatalf("purged '%d' jobs, expected '3'", len(purged))
+	}
+	a, err := job_hdl.NewArchiver(t.TempDir(), job_hdl.WithArchiveClock(clock), job_hdl.WithArchiveRotation(1<<20, time.Hour), job_hdl.WithArchiveRetention(0, 3*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = a.Append(purged[:2]); err != nil {
+		t.Fatal(err)
+	}
+	if err = a.Append(purged[2:]); err != nil {
+		t.Fatal(err)
+	}
+	if got := archivedIDs(t, a, job_hdl.ArchiveQuery{}); len(got) != 3 || got[2] != ids[2] {
+		t.Errorf("archived jobs are '%v'", got)
+	}
+	if got := archivedIDs(t, a, job_hdl.ArchiveQuery{ID: ids[1]}); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("jobs found by id are '%v'", got)
+	}
+	if got := archivedIDs(t, a, job_hdl.ArchiveQuery{Since: purged[0].Created, Until: purged[2].Created}); len(got) != 1 || got[0] != ids[1] {
+		t.Errorf("jobs found by time range are '%v'", got)
+	}
+	clock.Advance(2 * time.Hour)
+	purged, err = hdl.Purge(ctx, time.Hour, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = a.Append(purged); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(3 * time.Hour)
+	if err = a.Append([]lib.Job{{ID: "last", Created: clock.Now()}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := archivedIDs(t, a, job_hdl.ArchiveQuery{}); len(got) != 2 || got[0] != ids[3] {
+		t.Errorf("archived jobs after retention are '%v'", got)
+	}
+}
+
+func TestPurgeArchiveFailure(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	id, err := hdl.Create(ctx, "test", jhtest.Result(1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	dir := filepath.Join(t.TempDir(), "archive")
+	a, err := job_hdl.NewArchiver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(dir, nil, 0o660); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hdl.Purge(ctx, 0, a.Append); err == nil {
+		t.Error("purged without archiving")
+	}
+	jhtest.AssertResult(t, jhtest.GetJob(t, hdl, id), 1)
+}
+
+// purgeSignal signals each call of Purge.
+type purgeSignal struct {
+	*job_hdl.Handler
+	called chan struct{}
+}
+
+func (h *purgeSignal) Purge(ctx context.Context, maxAge time.Duration, store func([]lib.Job) error) ([]lib.Job, error) {
+	select {
+	case h.called <- struct{}{}:
+	case <-ctx.Done():
+	}
+	return h.Handler.Purge(ctx, maxAge, store)
+}
+
+func TestPurgeJobsHandlerArchive(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx, cf := context.WithCancel(context.Background())
+	id, err := hdl.Create(ctx, "test", jhtest.Result(1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	a, err := job_hdl.NewArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := &purgeSignal{Handler: hdl, called: make(chan struct{})}
+	ph := job_hdl.NewPurgeJobsHandler(sig, time.Millisecond, 0, job_hdl.WithArchiver(a))
+	ph.Start(ctx)
+	// the second call starts after the jobs purged by the first call are archived
+	for i := 0; i < 2; i++ {
+		select {
+		case <-sig.called:
+		case <-time.After(5 * time.Second):
+			t.Fatal("jobs not purged")
+		}
+	}
+	cf()
+	ph.Wait()
+	if len(archivedIDs(t, a, job_hdl.ArchiveQuery{ID: id})) != 1 {
+		t.Error("purged job not archived")
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"github.com/google/uuid"
+	"time"
+)
+
+type BatchItem struct {
+	Description string
+	Target      TargetFunc
+}
+
+// batch fields are guarded by the handler lock.
+type batch struct {
+	id      string
+	created time.Time
+	jobs    []*job
+}
+
+// CreateBatch creates a job for each item and returns the ID of the batch. The options apply to
+// every job, WithCacheKey is rejected since it identifies a single job.
+// Either all jobs are created or none, no job starts before the batch is complete.
+func (h *Handler) CreateBatch(ctx context.Context, items []BatchItem, opts ...CreateOption) (string, error) {
+	if len(items) == 0 {
+		err := errors.New("batch without items")
+		err = h.opt.invalidInputErr(err)
+		return "", err
+	}
+	cOpt := newCreateOptions(opts)
+	if cOpt.cacheKey != "" {
+		err := errors.New("cache key not supported for batches")
+		err = h.opt.invalidInputErr(err)
+		return "", err
+	}
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		err = h.opt.internalErr(err)
+		return "", err
+	}
+	b := &batch{id: uid.String(), created: h.opt.clock.Now().UTC()}
+	cOpt.batchID = b.id
+	for _, item := range items {
+		j, err := h.newJob(ctx, lib.Job{Description: item.Description}, item.Target, cOpt)
+		if err != nil {
+			for _, j := range b.jobs {
+				j.cFunc()
+			}
+			return "", err
+		}
+		b.jobs = append(b.jobs, j)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, j := range b.jobs {
+		if err = h.enqueue(j, cOpt); err != nil {
+			h.discard(b.jobs[:i], err)
+			for _, j := range b.jobs[i:] {
+				j.cFunc()
+			}
+			return "", fmt.Errorf("creating batch item %d failed: %w", i, err)
+		}
+	}
+	for i, j := range b.jobs {
+		if err = h.dispatch(j); err != nil {
+			h.discard(b.jobs, err)
+			return "", fmt.Errorf("creating batch item %d failed: %w", i, err)
+		}
+	}
+	for _, j := range b.jobs {
+		h.register(j)
+	}
+	h.batches[b.id] = b
+	return b.id, nil
+}
+
+// discard removes queued jobs of a batch that could not be created completely, the handler lock
+// must be held. Dispatchers already handed to an executor find the queue without these jobs.
+func (h *Handler) discard(jobs []*job, err error) {
+	for _, j := range jobs {
+		h.unqueue(j, err)
+	}
+}
+
+// GetBatch returns the jobs of a batch in creation order and their number per status. The count of
+// lib.JobCompleted includes jobs counted as lib.JobOK and lib.JobError.
+func (h *Handler) GetBatch(_ context.Context, id string) (lib.Batch, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	b, ok := h.batches[id]
+	if !ok {
+		err := fmt.Errorf("batch %s not found", id)
+		err = h.opt.notFoundErr(err)
+		return lib.Batch{}, err
+	}
+	res := lib.Batch{
+		ID:      b.id,
+		Created: b.created,
+		Total:   len(b.jobs),
+		Counts:  make(map[lib.JobStatus]int),
+		Jobs:    make([]lib.Job, 0, len(b.jobs)),
+	}
+	for _, j := range b.jobs {
+		m := j.Meta()
+		if j.pool != nil {
+			m.QueuePosition = j.pool.queue.position(j)
+		}
+		state := jobState(m)
+		res.Counts[state]++
+		if state == lib.JobOK || state == lib.JobError {
+			res.Counts[lib.JobCompleted]++
+		}
+		res.Jobs = append(res.Jobs, m)
+	}
+	return res, nil
+}
+
+// CancelBatch cancels all pending and running jobs of a batch at once, no job of the batch starts
+// while canceling.
+func (h *Handler) CancelBatch(_ context.Context, id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	b, ok := h.batches[id]
+	if !ok {
+		err := fmt.Errorf("batch %s not found", id)
+		err = h.opt.notFoundErr(err)
+		return err
+	}
+	for _, j := range b.jobs {
+		m := j.Meta()
+		if m.Completed != nil || m.Canceled != nil {
+			continue
+		}
+		j.dequeue()
+		j.Cancel()
+	}
+	return nil
+}
+
+// purgeBatches removes purged jobs from batches and batches without jobs, the handler lock must be held.
+func (h *Handler) purgeBatches() {
+	for id, b := range h.batches {
+		jobs := b.jobs[:0]
+		for _, j := range b.jobs {
+			if _, ok := h.jobs[j.ID]; ok {
+				jobs = append(jobs, j)
+			}
+		}
+		b.jobs = jobs
+		if len(b.jobs) == 0 {
+			delete(h.batches, id)
+		}
+	}
+}
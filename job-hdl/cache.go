@@ -0,0 +1,56 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+
+// cached returns the job registered for a cache key if it is still pending or running or if it
+// completed without error within its TTL. The handler lock must be held.
+func (h *Handler) cached(key string) (*job, bool) {
+	j, ok := h.cache[key]
+	if !ok {
+		return nil, false
+	}
+	m := j.Meta()
+	if m.Canceled != nil || j.IsCanceled() {
+		return nil, false
+	}
+	if m.Completed == nil {
+		return j, true
+	}
+	if m.Error == nil && h.opt.clock.Now().Sub(*m.Completed) < j.cacheTTL {
+		return j, true
+	}
+	return nil, false
+}
+
+// attach adds callbacks and webhooks to a job, they are called right away if the job is already done.
+func (j *job) attach(callbacks []func(lib.Job), webhooks []string) {
+	if len(callbacks) == 0 && len(webhooks) == 0 {
+		return
+	}
+	j.mu.Lock()
+	if !j.notified {
+		j.callbacks = append(j.callbacks, callbacks...)
+		j.webhooks = append(j.webhooks, webhooks...)
+		j.mu.Unlock()
+		return
+	}
+	meta := j.Job
+	j.mu.Unlock()
+	go j.opt.deliver(j.hCtx, meta, callbacks, webhooks)
+}
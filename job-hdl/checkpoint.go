@@ -0,0 +1,265 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CheckpointRecord holds everything needed to re-enqueue a job of a resumable job type.
+type CheckpointRecord struct {
+	JobID       string          `json:"job_id"`
+	Type        string          `json:"type"`
+	Params      json.RawMessage `json:"params"`
+	Description string          `json:"description"`
+	Owner       string          `json:"owner"`
+	Priority    lib.JobPriority `json:"priority"`
+	Pool        string          `json:"pool"`
+	Created     time.Time       `json:"created"`
+	Updated     time.Time       `json:"updated"`
+	Data        []byte          `json:"data"`
+}
+
+type CheckpointStore interface {
+	Put(ctx context.Context, rec CheckpointRecord) error
+	Get(ctx context.Context, jobID string) (CheckpointRecord, bool, error)
+	Delete(ctx context.Context, jobID string) error
+	List(ctx context.Context, jobType string) ([]CheckpointRecord, error)
+}
+
+// Checkpointer saves and loads opaque state of a running job.
+type Checkpointer interface {
+	Save(data []byte) error
+	// Load returns the last saved state or nil.
+	Load() []byte
+}
+
+type jobCtxKey struct{}
+
+func jobFromContext(ctx context.Context) (*job, bool) {
+	j, ok := ctx.Value(jobCtxKey{}).(*job)
+	return j, ok
+}
+
+// GetCheckpointer returns the checkpointer of the job the context was passed to. The second return
+// value is false if the job is not of a resumable job type or no CheckpointStore is configured.
+func GetCheckpointer(ctx context.Context) (Checkpointer, bool) {
+	j, ok := jobFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	j.cpMu.Lock()
+	defer j.cpMu.Unlock()
+	if j.cpRec == nil {
+		return nil, false
+	}
+	return &jobCheckpointer{j: j}, true
+}
+
+type jobCheckpointer struct {
+	j *job
+}
+
+func (c *jobCheckpointer) Save(data []byte) error {
+	c.j.cpMu.Lock()
+	defer c.j.cpMu.Unlock()
+	if c.j.cpReleased {
+		return fmt.Errorf("job '%s' checkpoint released", c.j.ID)
+	}
+	rec := *c.j.cpRec
+	rec.Data = data
+	rec.Updated = c.j.opt.clock.Now().UTC()
+	if err := c.j.opt.checkpoints.Put(c.j.hCtx, rec); err != nil {
+		return err
+	}
+	c.j.cpRec = &rec
+	return nil
+}
+
+func (c *jobCheckpointer) Load() []byte {
+	c.j.cpMu.Lock()
+	defer c.j.cpMu.Unlock()
+	return c.j.cpRec.Data
+}
+
+// releaseCheckpoint removes the record of a job unless keep is set, e.g. for jobs stopped because
+// the handler context is done. The checkpointer of the job stops saving records in either case.
+func (j *job) releaseCheckpoint(keep bool) {
+	j.cpMu.Lock()
+	defer j.cpMu.Unlock()
+	if j.cpRec == nil || j.cpReleased {
+		return
+	}
+	j.cpReleased = true
+	if keep {
+		return
+	}
+	if err := j.opt.checkpoints.Delete(context.Background(), j.ID); err != nil {
+		j.opt.errorf("job '%s' removing checkpoint failed: %s", j.ID, err)
+	}
+}
+
+// resumeJobs re-enqueues stored jobs of a resumable job type with their last checkpoint.
+func (h *Handler) resumeJobs(jt JobType) {
+	recs, err := h.opt.checkpoints.List(h.ctx, jt.Name)
+	if err != nil {
+		h.opt.errorf("listing checkpoints of job type '%s' failed: %s", jt.Name, err)
+		return
+	}
+	for _, rec := range recs {
+		h.mu.RLock()
+		_, ok := h.jobs[rec.JobID]
+		h.mu.RUnlock()
+		if ok {
+			continue
+		}
+		tFunc, err := jt.Factory(rec.Params)
+		if err != nil {
+			h.opt.errorf("resuming job '%s' failed: %s", rec.JobID, err)
+			if err = h.opt.checkpoints.Delete(h.ctx, rec.JobID); err != nil {
+				h.opt.errorf("job '%s' removing checkpoint failed: %s", rec.JobID, err)
+			}
+			continue
+		}
+		r := rec
+		_, err = h.create(h.ctx, lib.Job{Description: rec.Description, Type: rec.Type}, tFunc, []CreateOption{
+			WithOwner(rec.Owner),
+			WithPriority(rec.Priority),
+			UsePool(rec.Pool),
+			func(o *createOptions) {
+				o.checkpoint = &r
+			},
+		})
+		if err != nil {
+			h.opt.errorf("resuming job '%s' failed: %s", rec.JobID, err)
+			continue
+		}
+		h.opt.debugf("resumed job '%s'", rec.JobID)
+	}
+}
+
+// FileCheckpointStore keeps one JSON file per job in a directory.
+type FileCheckpointStore struct {
+	dir string
+	mu  sync.RWMutex
+}
+
+func NewFileCheckpointStore(dir string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0770); err != nil {
+		return nil, err
+	}
+	return &FileCheckpointStore{dir: dir}, nil
+}
+
+func (s *FileCheckpointStore) Put(_ context.Context, rec CheckpointRecord) error {
+	p, err := s.path(rec.JobID)
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp := p + ".tmp"
+	if err = os.WriteFile(tmp, b, 0660); err != nil {
+		return err
+	}
+	return os.Rename(tmp, p)
+}
+
+func (s *FileCheckpointStore) Get(_ context.Context, jobID string) (CheckpointRecord, bool, error) {
+	p, err := s.path(jobID)
+	if err != nil {
+		return CheckpointRecord{}, false, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rec, err := readCheckpointFile(p)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return CheckpointRecord{}, false, nil
+		}
+		return CheckpointRecord{}, false, err
+	}
+	return rec, true, nil
+}
+
+func (s *FileCheckpointStore) Delete(_ context.Context, jobID string) error {
+	p, err := s.path(jobID)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err = os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileCheckpointStore) List(_ context.Context, jobType string) ([]CheckpointRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var recs []CheckpointRecord
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+		rec, err := readCheckpointFile(filepath.Join(s.dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if jobType == "" || rec.Type == jobType {
+			recs = append(recs, rec)
+		}
+	}
+	return recs, nil
+}
+
+func (s *FileCheckpointStore) path(jobID string) (string, error) {
+	if jobID == "" || strings.ContainsAny(jobID, "/\\") || strings.HasPrefix(jobID, ".") {
+		return "", fmt.Errorf("invalid job id '%s'", jobID)
+	}
+	return filepath.Join(s.dir, jobID+".json"), nil
+}
+
+func readCheckpointFile(p string) (CheckpointRecord, error) {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return CheckpointRecord{}, err
+	}
+	var rec CheckpointRecord
+	if err = json.Unmarshal(b, &rec); err != nil {
+		return CheckpointRecord{}, fmt.Errorf("decoding '%s' failed: %s", p, err)
+	}
+	return rec, nil
+}
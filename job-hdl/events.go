@@ -0,0 +1,124 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const DefaultEventBuffer = 1000
+
+// eventBroker distributes job updates to subscribers and keeps the most recent events so
+// subscribers can resume. Event IDs consist of an epoch identifying the broker instance and a
+// sequence number, IDs of another epoch cause all buffered events to be replayed.
+type eventBroker struct {
+	mu    sync.Mutex
+	epoch string
+	seq   uint64
+	buf   []lib.JobEvent
+	size  int
+	subs  map[*Subscription]struct{}
+}
+
+// Subscription receives job events until closed. The channel is closed if the subscriber does not
+// keep up, in which case it should subscribe again with the ID of the last received event.
+type Subscription struct {
+	C      <-chan lib.JobEvent
+	ch     chan lib.JobEvent
+	broker *eventBroker
+	once   sync.Once
+}
+
+func newEventBroker(epoch int64, size int) *eventBroker {
+	return &eventBroker{
+		epoch: strconv.FormatInt(epoch, 36),
+		size:  size,
+		subs:  make(map[*Subscription]struct{}),
+	}
+}
+
+func (b *eventBroker) publish(job lib.Job) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	e := lib.JobEvent{
+		ID:  fmt.Sprintf("%s-%d", b.epoch, b.seq),
+		Job: job,
+	}
+	if b.size > 0 {
+		if len(b.buf) >= b.size {
+			copy(b.buf, b.buf[1:])
+			b.buf = b.buf[:len(b.buf)-1]
+		}
+		b.buf = append(b.buf, e)
+	}
+	for s := range b.subs {
+		select {
+		case s.ch <- e:
+		default:
+			delete(b.subs, s)
+			close(s.ch)
+		}
+	}
+}
+
+func (b *eventBroker) subscribe(lastEventID string) *Subscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var backlog []lib.JobEvent
+	if lastEventID != "" {
+		backlog = b.buf
+		epoch, seqStr, ok := strings.Cut(lastEventID, "-")
+		if seq, err := strconv.ParseUint(seqStr, 10, 64); ok && err == nil && epoch == b.epoch {
+			first := b.seq - uint64(len(b.buf)) + 1
+			switch {
+			case seq >= b.seq:
+				backlog = nil
+			case seq >= first:
+				backlog = b.buf[seq-first+1:]
+			}
+		}
+	}
+	ch := make(chan lib.JobEvent, len(backlog)+64)
+	for _, e := range backlog {
+		ch <- e
+	}
+	s := &Subscription{C: ch, ch: ch, broker: b}
+	b.subs[s] = struct{}{}
+	return s
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.broker.mu.Lock()
+		defer s.broker.mu.Unlock()
+		if _, ok := s.broker.subs[s]; ok {
+			delete(s.broker.subs, s)
+			close(s.ch)
+		}
+	})
+}
+
+// Subscribe returns a subscription receiving an event for each job change. If lastEventID is not
+// empty, buffered events following that event are delivered first.
+func (h *Handler) Subscribe(lastEventID string) *Subscription {
+	return h.events.subscribe(lastEventID)
+}
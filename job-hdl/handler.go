@@ -18,8 +18,8 @@ package job_hdl
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"github.com/SENERGY-Platform/go-cc-job-handler/ccjh"
 	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
 	"github.com/google/uuid"
 	"sort"
@@ -28,51 +28,182 @@ import (
 )
 
 type Handler struct {
-	mu        sync.RWMutex
-	ctx       context.Context
-	ccHandler *ccjh.Handler
-	jobs      map[string]*job
+	mu      sync.RWMutex
+	ctx     context.Context
+	jobs    map[string]*job
+	pools   map[string]*pool
+	cache   map[string]*job
+	batches map[string]*batch
+	events  *eventBroker
+	stats   *statsCollector
+	types   map[string]JobType
+	tMu     sync.RWMutex
+	opt     options
 }
 
-func New(ctx context.Context, ccHandler *ccjh.Handler) *Handler {
-	return &Handler{
-		ctx:       ctx,
-		ccHandler: ccHandler,
-		jobs:      make(map[string]*job),
+func New(ctx context.Context, ccHandler Executor, opts ...Option) *Handler {
+	h := &Handler{
+		ctx:     ctx,
+		jobs:    make(map[string]*job),
+		cache:   make(map[string]*job),
+		batches: make(map[string]*batch),
+		types:   make(map[string]JobType),
+		opt:     newOptions(opts),
+	}
+	h.pools = newPools(ccHandler, h.opt.pools, &h.opt)
+	h.events = newEventBroker(h.opt.clock.Now().UnixNano(), h.opt.eventBuffer)
+	h.stats = newStatsCollector(&h.opt)
+	if h.opt.stuckInterval > 0 && (h.opt.maxRuntime > 0 || h.opt.cancelGrace > 0) {
+		go h.watchStuck()
 	}
+	return h
+}
+
+func (h *Handler) Create(ctx context.Context, desc string, tFunc TargetFunc) (string, error) {
+	return h.create(ctx, lib.Job{Description: desc}, tFunc, nil)
 }
 
-func (h *Handler) Create(_ context.Context, desc string, tFunc TargetFunc) (string, error) {
-	uid, err := uuid.NewRandom()
+// CreateWith creates a job like Create and applies the options.
+func (h *Handler) CreateWith(ctx context.Context, desc string, tFunc TargetFunc, opts ...CreateOption) (string, error) {
+	return h.create(ctx, lib.Job{Description: desc}, tFunc, opts)
+}
+
+func (h *Handler) create(cCtx context.Context, meta lib.Job, tFunc TargetFunc, opts []CreateOption) (string, error) {
+	cOpt := newCreateOptions(opts)
+	j, err := h.newJob(cCtx, meta, tFunc, cOpt)
 	if err != nil {
-		if NewInternalErr != nil {
-			err = NewInternalErr(err)
-		}
 		return "", err
 	}
-	id := uid.String()
-	ctx, cf := context.WithCancel(h.ctx)
-	j := job{
-		tFunc: tFunc,
-		ctx:   ctx,
-		cFunc: cf,
-		Job: lib.Job{
-			ID:          id,
-			Created:     time.Now().UTC(),
-			Description: desc,
-		},
-	}
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	err = h.ccHandler.Add(&j)
-	if err != nil {
-		if NewInternalErr != nil {
-			err = NewInternalErr(err)
+	if j.cacheKey != "" {
+		if cj, ok := h.cached(j.cacheKey); ok {
+			j.cFunc()
+			cj.attach(j.callbacks, j.webhooks)
+			return cj.ID, nil
 		}
+	}
+	if err = h.enqueue(j, cOpt); err != nil {
+		j.cFunc()
+		return "", err
+	}
+	if err = h.dispatch(j); err != nil {
+		h.unqueue(j, err)
 		return "", err
 	}
-	h.jobs[id] = &j
-	return id, nil
+	h.register(j)
+	return j.ID, nil
+}
+
+// newJob builds a job from the create options without adding it to the handler.
+func (h *Handler) newJob(cCtx context.Context, meta lib.Job, tFunc TargetFunc, cOpt createOptions) (*job, error) {
+	if cOpt.id != "" {
+		meta.ID = cOpt.id
+		meta.Created = cOpt.created
+	} else if cOpt.checkpoint != nil && cOpt.checkpoint.JobID != "" {
+		meta.ID = cOpt.checkpoint.JobID
+		meta.Created = cOpt.checkpoint.Created
+	} else {
+		uid, err := uuid.NewRandom()
+		if err != nil {
+			err = h.opt.internalErr(err)
+			return nil, err
+		}
+		meta.ID = uid.String()
+		meta.Created = h.opt.clock.Now().UTC()
+	}
+	ctx, cf := context.WithCancel(h.ctx)
+	j := &job{
+		tFunc:     tFunc,
+		ctx:       ctx,
+		cFunc:     cf,
+		hCtx:      h.ctx,
+		opt:       &h.opt,
+		callbacks: cOpt.callbacks,
+		webhooks:  cOpt.webhooks,
+		cacheKey:  cOpt.cacheKey,
+		cacheTTL:  cOpt.cacheTTL,
+		events:    h.events,
+		stats:     h.stats,
+		Job:       meta,
+	}
+	j.BatchID = cOpt.batchID
+	j.Owner = cOpt.owner
+	j.Priority = cOpt.priority
+	j.Pool = DefaultPool
+	if cOpt.pool != "" {
+		j.Pool = cOpt.pool
+	}
+	if pj, ok := jobFromContext(cCtx); ok {
+		j.ParentID = pj.ID
+	}
+	j.traceParent, _ = lib.SpanContextFromContext(cCtx)
+	return j, nil
+}
+
+// enqueue checks the limits and pushes a job to the queue of its pool, the handler lock must be held.
+func (h *Handler) enqueue(j *job, cOpt createOptions) error {
+	p, ok := h.pools[j.Pool]
+	if !ok {
+		err := fmt.Errorf("pool '%s' not found", j.Pool)
+		err = h.opt.invalidInputErr(err)
+		return err
+	}
+	if _, ok = h.jobs[j.ID]; ok {
+		err := fmt.Errorf("%s already exists", j.ID)
+		err = h.opt.internalErr(err)
+		return err
+	}
+	if err := h.checkLimits(j.Owner, j.Type); err != nil {
+		err = h.opt.queueFullErr(err)
+		return err
+	}
+	if cOpt.checkpoint != nil && h.opt.checkpoints != nil {
+		rec := *cOpt.checkpoint
+		rec.JobID = j.ID
+		rec.Type = j.Type
+		rec.Description = j.Description
+		rec.Owner = j.Owner
+		rec.Priority = j.Priority
+		rec.Pool = j.Pool
+		rec.Created = j.Created
+		if err := h.opt.checkpoints.Put(h.ctx, rec); err != nil {
+			err = h.opt.internalErr(err)
+			return err
+		}
+		j.cpRec = &rec
+	}
+	j.startQueueSpan()
+	j.pool = p
+	p.queue.push(j)
+	return nil
+}
+
+// dispatch hands a dispatcher for a queued job to the executor of its pool. Queued jobs are not
+// started before the handler lock is released.
+func (h *Handler) dispatch(j *job) error {
+	if err := j.pool.exec.Add(&dispatcher{h: h, p: j.pool}); err != nil {
+		err = h.opt.queueFullErr(fmt.Errorf("%w: %s", ErrQueueFull, err))
+		return err
+	}
+	return nil
+}
+
+// unqueue reverts enqueue for a job that is not registered yet, the handler lock must be held.
+func (h *Handler) unqueue(j *job, err error) {
+	j.pool.queue.remove(j)
+	j.endQueueSpan(err)
+	j.releaseCheckpoint(j.hCtx.Err() != nil)
+	j.cFunc()
+}
+
+// register adds a queued job to the handler, the handler lock must be held.
+func (h *Handler) register(j *job) {
+	h.jobs[j.ID] = j
+	if j.cacheKey != "" {
+		h.cache[j.cacheKey] = j
+	}
+	j.changed()
 }
 
 func (h *Handler) Get(_ context.Context, id string) (lib.Job, error) {
@@ -81,86 +212,229 @@ func (h *Handler) Get(_ context.Context, id string) (lib.Job, error) {
 	j, ok := h.jobs[id]
 	if !ok {
 		err := fmt.Errorf("%s not found", id)
-		if NewNotFoundErr != nil {
-			err = NewNotFoundErr(err)
-		}
+		err = h.opt.notFoundErr(err)
 		return lib.Job{}, err
 	}
-	return j.Meta(), nil
+	m := j.Meta()
+	if j.pool != nil {
+		m.QueuePosition = j.pool.queue.position(j)
+	}
+	return m, nil
 }
 
 func (h *Handler) Cancel(_ context.Context, id string) error {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
 	j, ok := h.jobs[id]
 	if !ok {
 		err := fmt.Errorf("%s not found", id)
-		if NewNotFoundErr != nil {
-			err = NewNotFoundErr(err)
-		}
+		err = h.opt.notFoundErr(err)
+		return err
+	}
+	if j.readOnly {
+		err := fmt.Errorf("%s is read-only", id)
+		err = h.opt.invalidInputErr(err)
 		return err
 	}
+	j.dequeue()
 	j.Cancel()
 	return nil
 }
 
-func (h *Handler) List(_ context.Context, filter lib.JobFilter) ([]lib.Job, error) {
-	if filter.Status != "" {
-		_, ok := jobStateMap[filter.Status]
-		if !ok {
-			err := fmt.Errorf("unknown job status '%s'", filter.Status)
-			if NewInvalidInputError != nil {
-				err = NewInvalidInputError(err)
-			}
-			return nil, err
+func (h *Handler) CancelMatching(_ context.Context, filter lib.JobFilter) ([]string, error) {
+	if err := h.validateFilter(filter); err != nil {
+		return nil, err
+	}
+	var ids []string
+	tNow := h.opt.clock.Now().UTC()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for id, j := range h.jobs {
+		m := j.Meta()
+		if j.readOnly || m.Completed != nil || m.Canceled != nil || j.IsCanceled() {
+			continue
 		}
+		if check(filter, m, tNow) {
+			j.dequeue()
+			j.Cancel()
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (h *Handler) List(_ context.Context, filter lib.JobFilter) ([]lib.Job, error) {
+	if err := h.validateFilter(filter); err != nil {
+		return nil, err
 	}
 	var jobs []lib.Job
+	tNow := h.opt.clock.Now().UTC()
 	h.mu.RLock()
 	defer h.mu.RUnlock()
-	for _, v := range h.jobs {
-		if check(filter, v.Meta()) {
-			jobs = append(jobs, v.Meta())
+	positions := make(map[string]int)
+	for _, p := range h.pools {
+		for id, pos := range p.queue.positions() {
+			positions[id] = pos
 		}
 	}
-	if filter.SortDesc {
-		sort.Slice(jobs, func(i, j int) bool {
-			return jobs[i].Created.After(jobs[j].Created)
-		})
-	} else {
-		sort.Slice(jobs, func(i, j int) bool {
-			return jobs[i].Created.Before(jobs[j].Created)
-		})
+	for _, v := range h.jobs {
+		m := v.Meta()
+		if check(filter, m, tNow) {
+			m.QueuePosition = positions[m.ID]
+			jobs = append(jobs, m)
+		}
 	}
+	sortJobs(jobs, filter, tNow)
 	return jobs, nil
 }
 
-func (h *Handler) PurgeJobs(_ context.Context, maxAge time.Duration) (int, error) {
-	var l []string
-	tNow := time.Now().UTC()
+func (h *Handler) PurgeJobs(ctx context.Context, maxAge time.Duration) (int, error) {
+	jobs, err := h.Purge(ctx, maxAge, nil)
+	return len(jobs), err
+}
+
+// Purge removes finished jobs older than maxAge and returns the removed jobs. If store is not nil, it
+// receives the jobs before they are removed and no job is removed if it fails.
+func (h *Handler) Purge(_ context.Context, maxAge time.Duration, store func([]lib.Job) error) ([]lib.Job, error) {
+	var jobs []lib.Job
+	tNow := h.opt.clock.Now().UTC()
 	h.mu.RLock()
-	for k, v := range h.jobs {
+	for _, v := range h.jobs {
 		m := v.Meta()
 		if v.IsCanceled() || m.Completed != nil || m.Canceled != nil {
 			if tNow.Sub(m.Created) >= maxAge {
-				l = append(l, k)
+				jobs = append(jobs, m)
 			}
 		}
 	}
 	h.mu.RUnlock()
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Created.Before(jobs[j].Created)
+	})
+	if store != nil && len(jobs) > 0 {
+		if err := store(jobs); err != nil {
+			err = h.opt.internalErr(err)
+			return nil, err
+		}
+	}
 	h.mu.Lock()
-	for _, id := range l {
-		delete(h.jobs, id)
+	defer h.mu.Unlock()
+	for _, m := range jobs {
+		j, ok := h.jobs[m.ID]
+		if !ok {
+			continue
+		}
+		if j.cacheKey != "" && h.cache[j.cacheKey] == j {
+			delete(h.cache, j.cacheKey)
+		}
+		h.stats.remove(j)
+		delete(h.jobs, m.ID)
 	}
-	h.mu.Unlock()
-	return len(l), nil
+	h.purgeBatches()
+	return jobs, nil
 }
 
-func check(filter lib.JobFilter, job lib.Job) bool {
-	if !filter.Since.IsZero() && !job.Created.After(filter.Since) {
+func (h *Handler) validateFilter(filter lib.JobFilter) error {
+	if filter.Status != "" {
+		_, ok := jobStateMap[filter.Status]
+		if !ok {
+			err := fmt.Errorf("unknown job status '%s'", filter.Status)
+			err = h.opt.invalidInputErr(err)
+			return err
+		}
+	}
+	if filter.SortBy != "" {
+		_, ok := sortKeyMap[filter.SortBy]
+		if !ok {
+			err := fmt.Errorf("unknown sort key '%s'", filter.SortBy)
+			err = h.opt.invalidInputErr(err)
+			return err
+		}
+	}
+	if filter.MinDuration < 0 || filter.MaxDuration < 0 {
+		err := errors.New("negative duration")
+		err = h.opt.invalidInputErr(err)
+		return err
+	}
+	return nil
+}
+
+// sortJobs orders jobs by the sort key of the filter, jobs without a value for the key come last.
+// Ties are ordered by creation time.
+func sortJobs(jobs []lib.Job, filter lib.JobFilter, now time.Time) {
+	key := func(j lib.Job) (int64, bool) {
+		switch filter.SortBy {
+		case lib.SortByStarted:
+			if j.Started == nil {
+				return 0, false
+			}
+			return j.Started.UnixNano(), true
+		case lib.SortByCompleted:
+			if j.Completed == nil {
+				return 0, false
+			}
+			return j.Completed.UnixNano(), true
+		case lib.SortByDuration:
+			d, ok := runDuration(j, now)
+			return int64(d), ok
+		default:
+			return j.Created.UnixNano(), true
+		}
+	}
+	sort.SliceStable(jobs, func(i, j int) bool {
+		ki, oki := key(jobs[i])
+		kj, okj := key(jobs[j])
+		if oki != okj {
+			return oki
+		}
+		if ki == kj {
+			ki, kj = jobs[i].Created.UnixNano(), jobs[j].Created.UnixNano()
+		}
+		if filter.SortDesc {
+			return ki > kj
+		}
+		return ki < kj
+	})
+}
+
+// runDuration returns the run duration of started jobs, jobs still running are measured up to now.
+func runDuration(j lib.Job, now time.Time) (time.Duration, bool) {
+	if j.Started == nil {
+		return 0, false
+	}
+	if j.Completed == nil {
+		return now.Sub(*j.Started), true
+	}
+	return j.Completed.Sub(*j.Started), true
+}
+
+// inRange reports whether t is after since and before until, zero bounds are ignored. A missing
+// time only matches if no bound is set.
+func inRange(t *time.Time, since, until time.Time) bool {
+	if since.IsZero() && until.IsZero() {
+		return true
+	}
+	if t == nil {
 		return false
 	}
-	if !filter.Until.IsZero() && !job.Created.Before(filter.Until) {
+	return (since.IsZero() || t.After(since)) && (until.IsZero() || t.Before(until))
+}
+
+func check(filter lib.JobFilter, job lib.Job, now time.Time) bool {
+	if !inRange(&job.Created, filter.Since, filter.Until) || !inRange(job.Started, filter.StartedSince, filter.StartedUntil) || !inRange(job.Completed, filter.CompletedSince, filter.CompletedUntil) {
+		return false
+	}
+	if filter.MinDuration > 0 || filter.MaxDuration > 0 {
+		d, ok := runDuration(job, now)
+		if !ok || (filter.MinDuration > 0 && d < filter.MinDuration) || (filter.MaxDuration > 0 && d > filter.MaxDuration) {
+			return false
+		}
+	}
+	if filter.ErrorCode != nil && (job.Error == nil || job.Error.Code == nil || *job.Error.Code != *filter.ErrorCode) {
+		return false
+	}
+	if filter.Priority != nil && job.Priority != *filter.Priority {
 		return false
 	}
 	switch filter.Status {
@@ -192,6 +466,13 @@ func check(filter lib.JobFilter, job lib.Job) bool {
 	return true
 }
 
+var sortKeyMap = map[lib.JobSortKey]struct{}{
+	lib.SortByCreated:   {},
+	lib.SortByStarted:   {},
+	lib.SortByCompleted: {},
+	lib.SortByDuration:  {},
+}
+
 var jobStateMap = map[lib.JobStatus]struct{}{
 	lib.JobPending:   {},
 	lib.JobRunning:   {},
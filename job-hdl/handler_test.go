@@ -0,0 +1,957 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/go-cc-job-handler/ccjh"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestHandler() (*job_hdl.Handler, *jhtest.Executor, *jhtest.Clock) {
+	exec := jhtest.NewExecutor()
+	clock := jhtest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	return job_hdl.New(context.Background(), exec, job_hdl.WithClock(clock)), exec, clock
+}
+
+var _ interface {
+	job_hdl.JobHandler
+	job_hdl.OptionCreator
+	job_hdl.Submitter
+	job_hdl.MatchCanceler
+	job_hdl.BatchHandler
+	job_hdl.Purger
+	job_hdl.Monitor
+	job_hdl.Snapshotter
+	job_hdl.TraceExporter
+} = (*job_hdl.Handler)(nil)
+
+func TestHandlerLifecycle(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	var id string
+	id, err := hdl.Create(ctx, "test", func(context.Context, context.CancelFunc) (any, error) {
+		jhtest.AssertStatus(t, jhtest.GetJob(t, hdl, id), lib.JobRunning)
+		clock.Advance(time.Second)
+		return 1, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jhtest.AssertStatus(t, jhtest.GetJob(t, hdl, id), lib.JobPending)
+	if !exec.RunNext() {
+		t.Fatal("no job run")
+	}
+	j := jhtest.GetJob(t, hdl, id)
+	jhtest.AssertResult(t, j, 1)
+	if j.Completed.Sub(*j.Started) != time.Second {
+		t.Errorf("run duration is '%s', expected '1s'", j.Completed.Sub(*j.Started))
+	}
+	id2, err := hdl.Create(ctx, "test", jhtest.Result(nil, errors.New("test error")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	jhtest.AssertError(t, jhtest.GetJob(t, hdl, id2), "test error")
+}
+
+func TestHandlerCancel(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	id, err := hdl.Create(ctx, "test", jhtest.Result(1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = hdl.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if exec.RunNext() {
+		t.Error("canceled job run")
+	}
+	jhtest.AssertStatus(t, jhtest.GetJob(t, hdl, id), lib.JobCanceled)
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err = hdl.Create(ctx, "test", jhtest.Result(i, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	exec.RunNext()
+	canceled, err := hdl.CancelMatching(ctx, lib.JobFilter{Status: lib.JobPending})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(canceled) != 2 {
+		t.Errorf("canceled '%d' jobs, expected '2'", len(canceled))
+	}
+	jhtest.AssertResult(t, jhtest.GetJob(t, hdl, ids[0]), 0)
+	for _, id := range ids[1:] {
+		jhtest.AssertStatus(t, jhtest.GetJob(t, hdl, id), lib.JobCanceled)
+	}
+}
+
+func TestHandlerPurgeAndSnapshot(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := hdl.Create(ctx, "test", jhtest.Result(i, nil)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	exec.RunAll()
+	pID, err := hdl.Create(ctx, "pending", jhtest.Result(nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := hdl.Export(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	n, err := hdl.PurgeJobs(ctx, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("purged '%d' jobs, expected '2'", n)
+	}
+	hdl2, _, _ := newTestHandler()
+	if n, err = hdl2.Import(ctx, &buf); err != nil {
+		t.Fatal(err)
+	}
+	if n != 3 {
+		t.Errorf("imported '%d' jobs, expected '3'", n)
+	}
+	jhtest.AssertStatus(t, jhtest.GetJob(t, hdl2, pID), lib.JobCanceled)
+	if jobs, _ := hdl2.List(ctx, lib.JobFilter{Status: lib.JobPending}); len(jobs) != 0 {
+		t.Errorf("'%d' imported jobs pending", len(jobs))
+	}
+	if stats, _ := hdl2.Stats(ctx, 0); stats.Counts[lib.JobCanceled] != 1 || stats.Counts[lib.JobPending] != 0 {
+		t.Errorf("imported job counts are '%v'", stats.Counts)
+	}
+	jobs, err := hdl2.List(ctx, lib.JobFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, j := range jobs {
+		if j.ID != pID {
+			jhtest.AssertStatus(t, j, lib.JobOK)
+		}
+		if err = hdl2.Cancel(ctx, j.ID); err == nil {
+			t.Errorf("canceling imported job '%s' succeeded", j.ID)
+		}
+	}
+}
+
+func TestHandlerSubmit(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	err := hdl.RegisterJobType(job_hdl.JobType{
+		JobType: lib.JobType{
+			Name: "add",
+			Params: map[string]lib.JobParam{
+				"a": {Type: lib.ParamInteger, Required: true},
+				"b": {Type: lib.ParamInteger},
+			},
+		},
+		Factory: func(params json.RawMessage) (job_hdl.TargetFunc, error) {
+			var p struct{ A, B int }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return jhtest.Result(p.A+p.B, nil), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, params := range []string{`{"b":1}`, `{"a":"1"}`, `{"a":1,"c":1}`, `[]`} {
+		if _, err = hdl.Submit(ctx, "add", json.RawMessage(params)); err == nil {
+			t.Errorf("submitting '%s' succeeded", params)
+		}
+	}
+	if _, err = hdl.Submit(ctx, "sub", nil); err == nil {
+		t.Error("submitting unknown type succeeded")
+	}
+	id, err := hdl.Submit(ctx, "add", json.RawMessage(`{"a":1,"b":2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	j := jhtest.GetJob(t, hdl, id)
+	jhtest.AssertResult(t, j, 3)
+	if j.Type != "add" {
+		t.Errorf("job type is '%s', expected 'add'", j.Type)
+	}
+}
+
+func TestHandlerTracing(t *testing.T) {
+	rec := jhtest.NewRecorder()
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(context.Background(), exec, job_hdl.WithTracer(rec))
+	parent, err := lib.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var targetSc lib.SpanContext
+	id, err := hdl.Create(lib.ContextWithSpanContext(context.Background(), parent), "test", func(ctx context.Context, _ context.CancelFunc) (any, error) {
+		targetSc, _ = lib.SpanContextFromContext(ctx)
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	if j := jhtest.GetJob(t, hdl, id); j.TraceID != parent.TraceID {
+		t.Errorf("job trace id is '%s', expected '%s'", j.TraceID, parent.TraceID)
+	}
+	spans := rec.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded '%d' spans, expected '2'", len(spans))
+	}
+	queue, execute := spans[0], spans[1]
+	if queue.Name != job_hdl.QueueSpanName || execute.Name != job_hdl.ExecuteSpanName {
+		t.Errorf("span names are '%s' and '%s'", queue.Name, execute.Name)
+	}
+	for _, s := range spans {
+		if !s.Ended {
+			t.Errorf("span '%s' not ended", s.Name)
+		}
+		if s.Parent != parent {
+			t.Errorf("span '%s' parent is '%v', expected '%v'", s.Name, s.Parent, parent)
+		}
+	}
+	if len(execute.Links) != 1 || execute.Links[0] != queue.Context {
+		t.Errorf("execute span not linked to queue span")
+	}
+	if targetSc != execute.Context {
+		t.Errorf("target span context is '%v', expected '%v'", targetSc, execute.Context)
+	}
+}
+
+func TestHandlerJobErr(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	tErr := lib.NewJobErr(lib.ErrKindTransient, fmt.Errorf("reading device: %w", io.ErrUnexpectedEOF), true, map[string]any{"device": "d1"})
+	id, err := hdl.Create(ctx, "test", jhtest.Result(nil, fmt.Errorf("scan failed: %w", tErr)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	j := jhtest.GetJob(t, hdl, id)
+	jhtest.AssertError(t, j, "scan failed: reading device: unexpected EOF")
+	if j.Error.Kind != lib.ErrKindTransient || !j.Error.Retriable || j.Error.Details["device"] != "d1" {
+		t.Errorf("unexpected job error '%+v'", j.Error)
+	}
+	causes := []string{"reading device: unexpected EOF", "unexpected EOF"}
+	if !reflect.DeepEqual(j.Error.Causes, causes) {
+		t.Errorf("causes are '%v', expected '%v'", j.Error.Causes, causes)
+	}
+}
+
+func TestHandlerNilJobErr(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	id, err := hdl.Create(context.Background(), "test", func(context.Context, context.CancelFunc) (any, error) {
+		return 1, lib.NewJobErr(lib.ErrKindTransient, nil, true, nil)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	j := jhtest.GetJob(t, hdl, id)
+	jhtest.AssertStatus(t, j, lib.JobOK)
+	jhtest.AssertResult(t, j, 1)
+}
+
+type notFoundErr struct {
+	error
+}
+
+func TestHandlerOptions(t *testing.T) {
+	hdl := job_hdl.New(context.Background(), jhtest.NewExecutor(), job_hdl.WithNotFoundErr(func(err error) error {
+		return &notFoundErr{err}
+	}))
+	hdl2 := job_hdl.New(context.Background(), jhtest.NewExecutor())
+	var nfErr *notFoundErr
+	if _, err := hdl.Get(context.Background(), "test"); !errors.As(err, &nfErr) {
+		t.Errorf("error '%v' not wrapped", err)
+	}
+	if _, err := hdl2.Get(context.Background(), "test"); errors.As(err, &nfErr) {
+		t.Errorf("error '%v' wrapped", err)
+	}
+}
+
+func TestHandlerQueueLimits(t *testing.T) {
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(context.Background(), exec, job_hdl.WithMaxPending(3, 2, 0))
+	ctx := context.Background()
+	var ids []string
+	for _, owner := range []string{"a", "a", "b"} {
+		id, err := hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.WithOwner(owner))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if _, err := hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.WithOwner("c")); !errors.Is(err, job_hdl.ErrQueueFull) {
+		t.Errorf("error is '%v', expected '%v'", err, job_hdl.ErrQueueFull)
+	}
+	for i, id := range ids {
+		if p := jhtest.GetJob(t, hdl, id).QueuePosition; p != i+1 {
+			t.Errorf("job '%s' queue position is '%d', expected '%d'", id, p, i+1)
+		}
+	}
+	exec.RunNext()
+	if p := jhtest.GetJob(t, hdl, ids[0]).QueuePosition; p != 0 {
+		t.Errorf("completed job queue position is '%d', expected '0'", p)
+	}
+	if _, err := hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.WithOwner("b")); err != nil {
+		t.Error(err)
+	}
+	if _, err := hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.WithOwner("b")); !errors.Is(err, job_hdl.ErrQueueFull) {
+		t.Errorf("error is '%v', expected '%v'", err, job_hdl.ErrQueueFull)
+	}
+}
+
+func TestHandlerPriority(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	var order []lib.JobPriority
+	create := func(p lib.JobPriority) string {
+		id, err := hdl.CreateWith(ctx, "test", func(context.Context, context.CancelFunc) (any, error) {
+			order = append(order, p)
+			return nil, nil
+		}, job_hdl.WithPriority(p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	low := create(lib.PriorityLow)
+	create(lib.PriorityNormal)
+	high := create(lib.PriorityHigh)
+	if p := jhtest.GetJob(t, hdl, high).QueuePosition; p != 1 {
+		t.Errorf("high priority job queue position is '%d', expected '1'", p)
+	}
+	if p := jhtest.GetJob(t, hdl, low).QueuePosition; p != 3 {
+		t.Errorf("low priority job queue position is '%d', expected '3'", p)
+	}
+	prio := lib.PriorityHigh
+	if jobs, _ := hdl.List(ctx, lib.JobFilter{Priority: &prio}); len(jobs) != 1 || jobs[0].ID != high {
+		t.Errorf("priority filter returned '%d' jobs", len(jobs))
+	}
+	exec.RunAll()
+	if !reflect.DeepEqual(order, []lib.JobPriority{lib.PriorityHigh, lib.PriorityNormal, lib.PriorityLow}) {
+		t.Errorf("dispatch order is '%v'", order)
+	}
+	order = nil
+	create(lib.PriorityLow)
+	clock.Advance(2 * job_hdl.DefaultPriorityAging)
+	create(lib.PriorityHigh)
+	exec.RunAll()
+	if !reflect.DeepEqual(order, []lib.JobPriority{lib.PriorityLow, lib.PriorityHigh}) {
+		t.Errorf("dispatch order with aging is '%v'", order)
+	}
+}
+
+func TestHandlerPools(t *testing.T) {
+	exec := jhtest.NewExecutor()
+	ioExec := jhtest.NewExecutor()
+	hdl := job_hdl.New(context.Background(), exec, job_hdl.WithPool("io", ioExec, 2))
+	ctx := context.Background()
+	if _, err := hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.UsePool("cpu")); err == nil {
+		t.Error("creating job in unknown pool succeeded")
+	}
+	var active lib.PoolInfo
+	id, err := hdl.CreateWith(ctx, "test", func(context.Context, context.CancelFunc) (any, error) {
+		pools, err := hdl.Pools(ctx)
+		if err != nil {
+			return nil, err
+		}
+		active = pools[1]
+		return nil, nil
+	}, job_hdl.UsePool("io"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hdl.CreateWith(ctx, "test", jhtest.Result(nil, nil), job_hdl.UsePool("io")); err != nil {
+		t.Fatal(err)
+	}
+	if exec.Pending() != 0 || ioExec.Pending() != 2 {
+		t.Errorf("executors pending '%d' and '%d', expected '0' and '2'", exec.Pending(), ioExec.Pending())
+	}
+	ioExec.RunNext()
+	if j := jhtest.GetJob(t, hdl, id); j.Pool != "io" {
+		t.Errorf("job pool is '%s', expected 'io'", j.Pool)
+	}
+	expected := lib.PoolInfo{Name: "io", Workers: 2, Active: 1, Pending: 1, Utilization: 0.5}
+	if active != expected {
+		t.Errorf("pool info is '%+v', expected '%+v'", active, expected)
+	}
+}
+
+func TestHandlerResume(t *testing.T) {
+	store, err := job_hdl.NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hCtx, stop := context.WithCancel(context.Background())
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(hCtx, exec, job_hdl.WithCheckpointStore(store))
+	newJobType := func(target job_hdl.TargetFunc) job_hdl.JobType {
+		return job_hdl.JobType{
+			JobType: lib.JobType{Name: "scan", Resumable: true},
+			Factory: func(json.RawMessage) (job_hdl.TargetFunc, error) {
+				return target, nil
+			},
+		}
+	}
+	err = hdl.RegisterJobType(newJobType(func(ctx context.Context, _ context.CancelFunc) (any, error) {
+		cp, ok := job_hdl.GetCheckpointer(ctx)
+		if !ok {
+			return nil, errors.New("no checkpointer")
+		}
+		if err := cp.Save([]byte("step 1")); err != nil {
+			return nil, err
+		}
+		stop()
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := hdl.Submit(context.Background(), "scan", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	exec2 := jhtest.NewExecutor()
+	hdl2 := job_hdl.New(context.Background(), exec2, job_hdl.WithCheckpointStore(store))
+	err = hdl2.RegisterJobType(newJobType(func(ctx context.Context, _ context.CancelFunc) (any, error) {
+		cp, _ := job_hdl.GetCheckpointer(ctx)
+		return string(cp.Load()), nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec2.RunAll()
+	jhtest.AssertResult(t, jhtest.GetJob(t, hdl2, id), "step 1")
+	if recs, _ := store.List(context.Background(), ""); len(recs) != 0 {
+		t.Errorf("'%d' checkpoints left, expected '0'", len(recs))
+	}
+}
+
+func TestHandlerCheckpointRelease(t *testing.T) {
+	store, err := job_hdl.NewFileCheckpointStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hCtx, stop := context.WithCancel(context.Background())
+	defer stop()
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(hCtx, exec, job_hdl.WithCheckpointStore(store))
+	saved := make(chan struct{})
+	var saveErr error
+	err = hdl.RegisterJobType(job_hdl.JobType{
+		JobType: lib.JobType{Name: "scan", Resumable: true, Params: map[string]lib.JobParam{"cancel": {Type: lib.ParamBool}}},
+		Factory: func(params json.RawMessage) (job_hdl.TargetFunc, error) {
+			var p struct{ Cancel bool }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return func(ctx context.Context, _ context.CancelFunc) (any, error) {
+				cp, _ := job_hdl.GetCheckpointer(ctx)
+				if err := cp.Save([]byte("step 1")); err != nil {
+					return nil, err
+				}
+				if p.Cancel {
+					close(saved)
+					<-ctx.Done()
+					saveErr = cp.Save([]byte("step 2"))
+					return nil, ctx.Err()
+				}
+				stop()
+				return "done", nil
+			}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	id, err := hdl.Submit(ctx, "scan", json.RawMessage(`{"cancel": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		exec.RunAll()
+		close(done)
+	}()
+	<-saved
+	if err = hdl.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	<-done
+	if saveErr == nil {
+		t.Error("checkpoint of canceled job saved")
+	}
+	if recs, _ := store.List(ctx, ""); len(recs) != 0 {
+		t.Errorf("'%d' checkpoints left after cancel, expected '0'", len(recs))
+	}
+	if _, err = hdl.Submit(ctx, "scan", json.RawMessage(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	if recs, _ := store.List(ctx, ""); len(recs) != 0 {
+		t.Errorf("'%d' checkpoints left after success during shutdown, expected '0'", len(recs))
+	}
+}
+
+func TestHandlerCache(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	runs := 0
+	target := func(context.Context, context.CancelFunc) (any, error) {
+		runs++
+		return runs, nil
+	}
+	var ids []string
+	cbks := 0
+	for i := 0; i < 2; i++ {
+		id, err := hdl.CreateWith(ctx, "scan", target, job_hdl.WithCacheKey("scan", time.Minute), job_hdl.WithCallback(func(lib.Job) {
+			cbks++
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+	}
+	if ids[0] != ids[1] {
+		t.Error("running job not reused")
+	}
+	exec.RunAll()
+	if cbks != 2 {
+		t.Errorf("callbacks called '%d' times, expected '2'", cbks)
+	}
+	clock.Advance(30 * time.Second)
+	id, err := hdl.CreateWith(ctx, "scan", target, job_hdl.WithCacheKey("scan", time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != ids[0] {
+		t.Error("cached result not reused")
+	}
+	clock.Advance(time.Minute)
+	if id, err = hdl.CreateWith(ctx, "scan", target, job_hdl.WithCacheKey("scan", time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	if id == ids[0] || runs != 2 {
+		t.Error("expired result reused")
+	}
+}
+
+func TestHandlerStats(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	run := func(d time.Duration, err error) job_hdl.TargetFunc {
+		return func(context.Context, context.CancelFunc) (any, error) {
+			clock.Advance(d)
+			return nil, err
+		}
+	}
+	for _, tFunc := range []job_hdl.TargetFunc{run(2*time.Second, nil), run(4*time.Second, errors.New("test error"))} {
+		if _, err := hdl.Create(ctx, "test", tFunc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.Advance(time.Second)
+	pID, err := hdl.Create(ctx, "pending", run(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cID, err := hdl.Create(ctx, "canceled", run(0, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = hdl.Cancel(ctx, cID); err != nil {
+		t.Fatal(err)
+	}
+	exec.RunNext()
+	exec.RunNext()
+	stats, err := hdl.Stats(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[lib.JobStatus]int{lib.JobPending: 1, lib.JobRunning: 0, lib.JobCanceled: 1, lib.JobCompleted: 2, lib.JobOK: 1, lib.JobError: 1}
+	for status, n := range counts {
+		if stats.Counts[status] != n {
+			t.Errorf("count of '%s' is '%d', expected '%d'", status, stats.Counts[status], n)
+		}
+	}
+	if stats.AvgRunTime != 3*time.Second || stats.P95RunTime != 4*time.Second {
+		t.Errorf("run times are '%s' and '%s', expected '3s' and '4s'", stats.AvgRunTime, stats.P95RunTime)
+	}
+	if stats.AvgQueueTime != 2*time.Second || stats.P95QueueTime != 3*time.Second {
+		t.Errorf("queue times are '%s' and '%s', expected '2s' and '3s'", stats.AvgQueueTime, stats.P95QueueTime)
+	}
+	if stats.FailureRate != 0.5 {
+		t.Errorf("failure rate is '%v', expected '0.5'", stats.FailureRate)
+	}
+	if stats.OldestPending == nil || stats.OldestPending.ID != pID {
+		t.Errorf("oldest pending job is '%v', expected '%s'", stats.OldestPending, pID)
+	}
+	if stats, err = hdl.Stats(ctx, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Finished != 1 || stats.FailureRate != 1 {
+		t.Errorf("window contains '%d' jobs with failure rate '%v', expected '1' and '1'", stats.Finished, stats.FailureRate)
+	}
+	clock.Advance(time.Hour)
+	if _, err = hdl.PurgeJobs(ctx, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if stats, err = hdl.Stats(ctx, 0); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Counts[lib.JobCompleted] != 0 || stats.Counts[lib.JobPending] != 1 {
+		t.Errorf("counts after purge are '%v'", stats.Counts)
+	}
+}
+
+func TestHandlerExportTrace(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	var cID string
+	pID, err := hdl.Create(ctx, "parent", func(ctx context.Context, _ context.CancelFunc) (any, error) {
+		var err error
+		cID, err = hdl.Create(ctx, "child", func(context.Context, context.CancelFunc) (any, error) {
+			clock.Advance(time.Second)
+			return nil, nil
+		})
+		clock.Advance(2 * time.Second)
+		return nil, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second)
+	exec.RunAll()
+	if p := jhtest.GetJob(t, hdl, cID).ParentID; p != pID {
+		t.Errorf("parent id is '%s', expected '%s'", p, pID)
+	}
+	var buf bytes.Buffer
+	if err = hdl.ExportTrace(ctx, &buf, lib.JobFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	var file job_hdl.TraceFile
+	if err = json.Unmarshal(buf.Bytes(), &file); err != nil {
+		t.Fatal(err)
+	}
+	type slice struct {
+		name, cat string
+		ts, dur   int64
+		tid       int
+	}
+	expected := []slice{
+		{"parent", job_hdl.TraceCatQueue, 0, 1e6, 2},
+		{"child", job_hdl.TraceCatQueue, 1e6, 2e6, 2},
+		{"parent", job_hdl.TraceCatExecute, 1e6, 2e6, 1},
+		{"child", job_hdl.TraceCatExecute, 3e6, 1e6, 1},
+	}
+	var slices []slice
+	var flows []job_hdl.TraceEvent
+	for _, e := range file.TraceEvents {
+		switch e.Ph {
+		case "X":
+			slices = append(slices, slice{e.Name, e.Cat, e.Ts, *e.Dur, e.Tid})
+		case "s", "f":
+			flows = append(flows, e)
+		}
+	}
+	if !reflect.DeepEqual(slices, expected) {
+		t.Errorf("slices are '%v', expected '%v'", slices, expected)
+	}
+	if len(flows) != 2 || flows[0].ID != cID || flows[0].Tid != 1 || flows[1].Tid != 2 || flows[1].Ts != 1e6 {
+		t.Errorf("unexpected flow events '%v'", flows)
+	}
+}
+
+func TestHandlerStuck(t *testing.T) {
+	exec := jhtest.NewExecutor()
+	clock := jhtest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	hdl := job_hdl.New(context.Background(), exec, job_hdl.WithClock(clock), job_hdl.WithMaxRuntime(time.Minute), job_hdl.WithCancelGracePeriod(10*time.Second))
+	ctx := context.Background()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	id, err := hdl.Create(ctx, "test", func(context.Context, context.CancelFunc) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		exec.RunNext()
+		close(done)
+	}()
+	<-started
+	if stuck, abandoned := hdl.CheckStuck(ctx); stuck != 0 || abandoned != 0 {
+		t.Errorf("'%d' stuck and '%d' abandoned jobs, expected none", stuck, abandoned)
+	}
+	clock.Advance(time.Minute)
+	if stuck, _ := hdl.CheckStuck(ctx); stuck != 1 || jhtest.GetJob(t, hdl, id).Stuck == nil {
+		t.Error("job not flagged as stuck")
+	}
+	if err = hdl.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if !hdl.IsHealthy() {
+		t.Error("unhealthy within grace period")
+	}
+	clock.Advance(10 * time.Second)
+	if hdl.IsHealthy() {
+		t.Error("healthy with abandoned job")
+	}
+	stats, err := hdl.Stats(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Stuck != 1 || stats.Abandoned != 1 || jhtest.GetJob(t, hdl, id).Abandoned == nil {
+		t.Errorf("'%d' stuck and '%d' abandoned jobs, expected '1' and '1'", stats.Stuck, stats.Abandoned)
+	}
+	close(release)
+	<-done
+	if stuck, abandoned := hdl.CheckStuck(ctx); stuck != 0 || abandoned != 0 || !hdl.IsHealthy() {
+		t.Errorf("'%d' stuck and '%d' abandoned jobs after completion, expected none", stuck, abandoned)
+	}
+}
+
+func TestHandlerStuckBackground(t *testing.T) {
+	exec := jhtest.NewExecutor()
+	clock := jhtest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	hCtx, cf := context.WithCancel(context.Background())
+	defer cf()
+	hdl := job_hdl.New(hCtx, exec, job_hdl.WithClock(clock), job_hdl.WithMaxRuntime(time.Minute), job_hdl.WithStuckCheckInterval(time.Millisecond))
+	sub := hdl.Subscribe("")
+	defer sub.Close()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	id, err := hdl.Create(context.Background(), "test", func(context.Context, context.CancelFunc) (any, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan struct{})
+	go func() {
+		exec.RunNext()
+		close(done)
+	}()
+	defer func() {
+		close(release)
+		<-done
+	}()
+	<-started
+	clock.Advance(time.Minute)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case e := <-sub.C:
+			if e.Job.ID == id && e.Job.Stuck != nil {
+				return
+			}
+		case <-timeout:
+			t.Fatal("job not flagged as stuck by background check")
+		}
+	}
+}
+
+func TestHandlerBatch(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	bID, err := hdl.CreateBatch(ctx, []job_hdl.BatchItem{
+		{Description: "ok", Target: jhtest.Result(1, nil)},
+		{Description: "error", Target: jhtest.Result(nil, errors.New("test error"))},
+		{Description: "pending", Target: jhtest.Result(3, nil)},
+	}, job_hdl.WithOwner("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunNext()
+	exec.RunNext()
+	if err = hdl.CancelBatch(ctx, bID); err != nil {
+		t.Fatal(err)
+	}
+	if exec.RunAll() != 0 {
+		t.Error("job of canceled batch run")
+	}
+	b, err := hdl.GetBatch(ctx, bID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	counts := map[lib.JobStatus]int{lib.JobOK: 1, lib.JobError: 1, lib.JobCompleted: 2, lib.JobCanceled: 1}
+	if b.Total != 3 || !reflect.DeepEqual(b.Counts, counts) {
+		t.Errorf("batch has '%d' jobs with counts '%v', expected '3' and '%v'", b.Total, b.Counts, counts)
+	}
+	for i, status := range []lib.JobStatus{lib.JobOK, lib.JobError, lib.JobCanceled} {
+		jhtest.AssertStatus(t, b.Jobs[i], status)
+		if b.Jobs[i].BatchID != bID || b.Jobs[i].Owner != "test" {
+			t.Errorf("job %d has batch '%s' and owner '%s'", i, b.Jobs[i].BatchID, b.Jobs[i].Owner)
+		}
+	}
+	if _, err = hdl.GetBatch(ctx, "unknown"); err == nil {
+		t.Error("unknown batch found")
+	}
+	hdl2 := job_hdl.New(context.Background(), jhtest.NewExecutor(), job_hdl.WithMaxPending(2, 0, 0))
+	items := []job_hdl.BatchItem{{Target: jhtest.Result(1, nil)}, {Target: jhtest.Result(2, nil)}, {Target: jhtest.Result(3, nil)}}
+	if _, err = hdl2.CreateBatch(ctx, items); !errors.Is(err, job_hdl.ErrQueueFull) {
+		t.Errorf("expected queue full error, got '%v'", err)
+	}
+	if jobs, _ := hdl2.List(ctx, lib.JobFilter{}); len(jobs) != 0 {
+		t.Errorf("'%d' jobs of failed batch kept", len(jobs))
+	}
+	if _, err = hdl.CreateBatch(ctx, items, job_hdl.WithCacheKey("test", time.Minute)); err == nil {
+		t.Error("batch with cache key created")
+	}
+}
+
+// failingExecutor fails to add jobs after a number of successful adds.
+type failingExecutor struct {
+	*jhtest.Executor
+	n int
+}
+
+func (e *failingExecutor) Add(job ccjh.Job) error {
+	if e.n == 0 {
+		return errors.New("test error")
+	}
+	e.n--
+	return e.Executor.Add(job)
+}
+
+func TestHandlerBatchAtomic(t *testing.T) {
+	exec := &failingExecutor{Executor: jhtest.NewExecutor(), n: 1}
+	hdl := job_hdl.New(context.Background(), exec)
+	ctx := context.Background()
+	run := false
+	target := func(context.Context, context.CancelFunc) (any, error) {
+		run = true
+		return nil, nil
+	}
+	items := []job_hdl.BatchItem{{Target: target}, {Target: target}}
+	if _, err := hdl.CreateBatch(ctx, items); !errors.Is(err, job_hdl.ErrQueueFull) {
+		t.Errorf("expected queue full error, got '%v'", err)
+	}
+	exec.RunAll()
+	if run {
+		t.Error("job of failed batch run")
+	}
+	if jobs, _ := hdl.List(ctx, lib.JobFilter{}); len(jobs) != 0 {
+		t.Errorf("'%d' jobs of failed batch kept", len(jobs))
+	}
+	if stats, _ := hdl.Stats(ctx, 0); stats.Counts[lib.JobPending] != 0 || stats.Counts[lib.JobCanceled] != 0 {
+		t.Errorf("stats of failed batch '%v'", stats.Counts)
+	}
+}
+
+func TestHandlerFilter(t *testing.T) {
+	hdl, exec, clock := newTestHandler()
+	ctx := context.Background()
+	run := func(d time.Duration, err error) job_hdl.TargetFunc {
+		return func(context.Context, context.CancelFunc) (any, error) {
+			clock.Advance(d)
+			return nil, err
+		}
+	}
+	code := 500
+	jErr := lib.NewJobErr(lib.ErrKindInternal, errors.New("test error"), false, nil)
+	jErr.Code = &code
+	var ids []string
+	for _, tFunc := range []job_hdl.TargetFunc{run(time.Second, nil), run(10*time.Minute, nil), run(6*time.Minute, jErr), run(0, nil)} {
+		id, err := hdl.Create(ctx, "test", tFunc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		clock.Advance(time.Millisecond)
+	}
+	exec.RunNext()
+	tFirst := clock.Now()
+	exec.RunNext()
+	exec.RunNext()
+	tests := []struct {
+		filter   lib.JobFilter
+		expected []string
+	}{
+		{lib.JobFilter{MinDuration: 5 * time.Minute}, []string{ids[1], ids[2]}},
+		{lib.JobFilter{MinDuration: 5 * time.Minute, SortBy: lib.SortByDuration, SortDesc: true}, []string{ids[1], ids[2]}},
+		{lib.JobFilter{MaxDuration: 7 * time.Minute}, []string{ids[0], ids[2]}},
+		{lib.JobFilter{ErrorCode: &code}, []string{ids[2]}},
+		{lib.JobFilter{CompletedSince: tFirst}, []string{ids[1], ids[2]}},
+		{lib.JobFilter{StartedUntil: tFirst.Add(time.Second)}, []string{ids[0], ids[1]}},
+		{lib.JobFilter{SortBy: lib.SortByCompleted, SortDesc: true}, []string{ids[2], ids[1], ids[0], ids[3]}},
+		{lib.JobFilter{SortBy: lib.SortByDuration}, []string{ids[0], ids[2], ids[1], ids[3]}},
+	}
+	for i, test := range tests {
+		jobs, err := hdl.List(ctx, test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, j := range jobs {
+			got = append(got, j.ID)
+		}
+		if !reflect.DeepEqual(got, test.expected) {
+			t.Errorf("filter %d returned '%v', expected '%v'", i, got, test.expected)
+		}
+	}
+	if _, err := hdl.List(ctx, lib.JobFilter{SortBy: "unknown"}); err == nil {
+		t.Error("unknown sort key accepted")
+	}
+	var running []lib.Job
+	id, err := hdl.Create(ctx, "running", func(context.Context, context.CancelFunc) (any, error) {
+		clock.Advance(time.Hour)
+		var err error
+		running, err = hdl.List(ctx, lib.JobFilter{MinDuration: 30 * time.Minute})
+		return nil, err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	if len(running) != 1 || running[0].ID != id {
+		t.Errorf("running jobs exceeding duration are '%v', expected '%s'", running, id)
+	}
+}
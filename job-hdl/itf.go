@@ -18,12 +18,24 @@ package job_hdl
 
 import (
 	"context"
+	"encoding/json"
+	"github.com/SENERGY-Platform/go-cc-job-handler/ccjh"
 	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
 	"time"
 )
 
 type TargetFunc func(context.Context, context.CancelFunc) (any, error)
 
+// Executor runs queued jobs, usually a *ccjh.Handler.
+type Executor interface {
+	Add(job ccjh.Job) error
+}
+
+type Clock interface {
+	Now() time.Time
+}
+
 type JobHandler interface {
 	Create(ctx context.Context, desc string, tFunc TargetFunc) (string, error)
 	Get(ctx context.Context, id string) (lib.Job, error)
@@ -32,15 +44,63 @@ type JobHandler interface {
 	PurgeJobs(ctx context.Context, maxAge time.Duration) (int, error)
 }
 
-var Logger interface {
+// OptionCreator creates jobs with options, e.g. callbacks, webhooks or a priority.
+type OptionCreator interface {
+	CreateWith(ctx context.Context, desc string, tFunc TargetFunc, opts ...CreateOption) (string, error)
+}
+
+// Submitter creates jobs of registered job types.
+type Submitter interface {
+	Submit(ctx context.Context, typeName string, params json.RawMessage, opts ...CreateOption) (string, error)
+	JobTypes(ctx context.Context) ([]lib.JobType, error)
+}
+
+// MatchCanceler cancels all jobs matching a filter.
+type MatchCanceler interface {
+	CancelMatching(ctx context.Context, filter lib.JobFilter) ([]string, error)
+}
+
+type BatchHandler interface {
+	CreateBatch(ctx context.Context, items []BatchItem, opts ...CreateOption) (string, error)
+	GetBatch(ctx context.Context, id string) (lib.Batch, error)
+	CancelBatch(ctx context.Context, id string) error
+}
+
+// Purger removes old jobs and returns them. If store is not nil, it receives the jobs before they are
+// removed, e.g. for archiving.
+type Purger interface {
+	Purge(ctx context.Context, maxAge time.Duration, store func([]lib.Job) error) ([]lib.Job, error)
+}
+
+// Monitor reports the state of pools and jobs.
+type Monitor interface {
+	Pools(ctx context.Context) ([]lib.PoolInfo, error)
+	Stats(ctx context.Context, window time.Duration) (lib.JobStats, error)
+	CheckStuck(ctx context.Context) (stuck, abandoned int)
+}
+
+type Snapshotter interface {
+	Export(ctx context.Context, w io.Writer) error
+	Import(ctx context.Context, r io.Reader) (int, error)
+}
+
+type TraceExporter interface {
+	ExportTrace(ctx context.Context, w io.Writer, filter lib.JobFilter) error
+}
+
+type Log interface {
 	Errorf(format string, arg ...any)
 	Warningf(format string, arg ...any)
 	Debugf(format string, arg ...any)
 }
 
+// Package level fallbacks for handlers not configured via options.
+var Logger Log
+
 var (
 	ErrCodeMapper        func(error) *int
 	NewInternalErr       func(error) error
 	NewNotFoundErr       func(error) error
 	NewInvalidInputError func(error) error
+	NewQueueFullErr      func(error) error
 )
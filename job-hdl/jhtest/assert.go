@@ -0,0 +1,102 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jhtest
+
+import (
+	"context"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"reflect"
+	"testing"
+)
+
+// Status returns the most specific status of a job: pending, running, canceled, error or ok.
+func Status(job lib.Job) lib.JobStatus {
+	switch {
+	case job.Canceled != nil && job.Completed == nil:
+		return lib.JobCanceled
+	case job.Completed != nil:
+		if job.Error != nil {
+			return lib.JobError
+		}
+		return lib.JobOK
+	case job.Started != nil:
+		return lib.JobRunning
+	default:
+		return lib.JobPending
+	}
+}
+
+// Result returns a TargetFunc that returns the given result and error.
+func Result(res any, err error) job_hdl.TargetFunc {
+	return func(context.Context, context.CancelFunc) (any, error) {
+		return res, err
+	}
+}
+
+func GetJob(t testing.TB, hdl job_hdl.JobHandler, id string) lib.Job {
+	t.Helper()
+	job, err := hdl.Get(context.Background(), id)
+	if err != nil {
+		t.Fatalf("getting job '%s' failed: %s", id, err)
+	}
+	return job
+}
+
+// AssertStatus fails the test if the job does not have the given status. The status
+// lib.JobCompleted matches both failed and successful jobs and lib.JobCanceled matches
+// jobs canceled while running.
+func AssertStatus(t testing.TB, job lib.Job, status lib.JobStatus) {
+	t.Helper()
+	s := Status(job)
+	if (status == lib.JobCompleted && job.Completed != nil) || (status == lib.JobCanceled && job.Canceled != nil) {
+		return
+	}
+	if s != status {
+		t.Errorf("job '%s' status is '%s', expected '%s'", job.ID, s, status)
+	}
+}
+
+func AssertResult(t testing.TB, job lib.Job, result any) {
+	t.Helper()
+	if job.Completed == nil {
+		t.Errorf("job '%s' not completed", job.ID)
+		return
+	}
+	if job.Error != nil {
+		t.Errorf("job '%s' got error: %s", job.ID, job.Error.Message)
+		return
+	}
+	if !reflect.DeepEqual(job.Result, result) {
+		t.Errorf("job '%s' result is '%v', expected '%v'", job.ID, job.Result, result)
+	}
+}
+
+func AssertError(t testing.TB, job lib.Job, message string) {
+	t.Helper()
+	if job.Completed == nil {
+		t.Errorf("job '%s' not completed", job.ID)
+		return
+	}
+	if job.Error == nil {
+		t.Errorf("job '%s' got no error, expected '%s'", job.ID, message)
+		return
+	}
+	if job.Error.Message != message {
+		t.Errorf("job '%s' error is '%s', expected '%s'", job.ID, job.Error.Message, message)
+	}
+}
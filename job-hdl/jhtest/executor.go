@@ -0,0 +1,73 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jhtest
+
+import (
+	"github.com/SENERGY-Platform/go-cc-job-handler/ccjh"
+	"sync"
+)
+
+// Executor queues jobs like ccjh.Handler but only runs them when stepped, on the calling goroutine.
+type Executor struct {
+	mu    sync.Mutex
+	queue []ccjh.Job
+}
+
+func NewExecutor() *Executor {
+	return &Executor{}
+}
+
+func (e *Executor) Add(job ccjh.Job) error {
+	e.mu.Lock()
+	e.queue = append(e.queue, job)
+	e.mu.Unlock()
+	return nil
+}
+
+func (e *Executor) Pending() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.queue)
+}
+
+// RunNext runs the next queued job to completion. Canceled jobs are dropped without being
+// run, as ccjh.Handler does. Returns false if no job was run.
+func (e *Executor) RunNext() bool {
+	for {
+		e.mu.Lock()
+		if len(e.queue) == 0 {
+			e.mu.Unlock()
+			return false
+		}
+		j := e.queue[0]
+		e.queue = e.queue[1:]
+		e.mu.Unlock()
+		if !j.IsCanceled() {
+			j.CallTarget(func() {})
+			return true
+		}
+	}
+}
+
+// RunAll runs queued jobs until the queue is empty, including jobs queued while running.
+func (e *Executor) RunAll() int {
+	n := 0
+	for e.RunNext() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package jhtest
+
+import (
+	"fmt"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"sync"
+)
+
+// Recorder is a job_hdl.Tracer that keeps all spans in memory. IDs are generated sequentially.
+type Recorder struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+	count uint64
+}
+
+type RecordedSpan struct {
+	Name       string
+	Context    lib.SpanContext
+	Parent     lib.SpanContext
+	Links      []lib.SpanContext
+	Attributes map[string]any
+	Errors     []error
+	Ended      bool
+	rec        *Recorder
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Start(parent lib.SpanContext, name string, links ...lib.SpanContext) job_hdl.Span {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.count++
+	sc := lib.SpanContext{
+		TraceID: parent.TraceID,
+		SpanID:  fmt.Sprintf("%016x", r.count),
+		Sampled: true,
+	}
+	if !parent.IsValid() {
+		sc.TraceID = fmt.Sprintf("%032x", r.count)
+	}
+	s := &RecordedSpan{
+		Name:       name,
+		Context:    sc,
+		Parent:     parent,
+		Links:      links,
+		Attributes: make(map[string]any),
+		rec:        r,
+	}
+	r.spans = append(r.spans, s)
+	return s
+}
+
+// Spans returns copies of all spans in the order they were started.
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := make([]RecordedSpan, 0, len(r.spans))
+	for _, s := range r.spans {
+		c := *s
+		c.Attributes = make(map[string]any, len(s.Attributes))
+		for k, v := range s.Attributes {
+			c.Attributes[k] = v
+		}
+		spans = append(spans, c)
+	}
+	return spans
+}
+
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.spans = nil
+	r.mu.Unlock()
+}
+
+func (s *RecordedSpan) SpanContext() lib.SpanContext {
+	return s.Context
+}
+
+func (s *RecordedSpan) SetAttribute(key string, value any) {
+	s.rec.mu.Lock()
+	s.Attributes[key] = value
+	s.rec.mu.Unlock()
+}
+
+func (s *RecordedSpan) RecordError(err error) {
+	s.rec.mu.Lock()
+	s.Errors = append(s.Errors, err)
+	s.rec.mu.Unlock()
+}
+
+func (s *RecordedSpan) End() {
+	s.rec.mu.Lock()
+	s.Ended = true
+	s.rec.mu.Unlock()
+}
@@ -25,43 +25,74 @@ import (
 )
 
 type job struct {
-	mu    sync.RWMutex
-	tFunc TargetFunc
-	ctx   context.Context
-	cFunc context.CancelFunc
+	mu          sync.RWMutex
+	tFunc       TargetFunc
+	ctx         context.Context
+	cFunc       context.CancelFunc
+	readOnly    bool
+	opt         *options
+	callbacks   []func(lib.Job)
+	webhooks    []string
+	cacheKey    string
+	cacheTTL    time.Duration
+	notified    bool
+	traceParent lib.SpanContext
+	qSpan       Span
+	pool        *pool
+	hCtx        context.Context
+	cpRec       *CheckpointRecord
+	cpMu        sync.Mutex
+	cpReleased  bool
+	events      *eventBroker
+	stats       *statsCollector
 	lib.Job
 }
 
 func (j *job) CallTarget(cbk func()) {
-	if Logger != nil {
-		Logger.Debugf("job '%s' starting ...", j.ID)
-	}
+	j.opt.debugf("job '%s' starting ...", j.ID)
 	j.mu.Lock()
-	t := time.Now().UTC()
+	if j.Canceled != nil {
+		// canceled after leaving the queue, Cancel already finished the job as pending
+		j.mu.Unlock()
+		cbk()
+		return
+	}
+	t := j.opt.clock.Now().UTC()
 	j.Started = &t
+	var qSc lib.SpanContext
+	if j.qSpan != nil {
+		qSc = j.qSpan.SpanContext()
+	}
+	j.endQueueSpan(nil)
 	j.mu.Unlock()
-	res, err := j.tFunc(j.ctx, j.cFunc)
+	j.changed()
+	ctx, span := j.startExecSpan(qSc)
+	res, err := j.tFunc(context.WithValue(ctx, jobCtxKey{}, j), j.cFunc)
+	if tErr, ok := err.(*lib.JobErr); ok && tErr == nil {
+		// a nil *JobErr returned by NewJobErr for a nil error is a success
+		err = nil
+	}
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
 	j.mu.Lock()
 	if err != nil {
-		j.Error = &lib.JobErr{
-			Message: err.Error(),
-		}
-		if ErrCodeMapper != nil {
-			j.Error.Code = ErrCodeMapper(err)
-		}
-		if Logger != nil {
-			Logger.Warningf("job '%s' got error: %s", j.ID, err.Error())
-		}
+		j.Error = j.opt.newJobErr(err)
+		j.opt.warningf("job '%s' got error: %s", j.ID, err.Error())
 	} else {
 		j.Result = res
 	}
-	t2 := time.Now().UTC()
+	t2 := j.opt.clock.Now().UTC()
 	j.Completed = &t2
 	j.mu.Unlock()
-	if Logger != nil {
-		Logger.Debugf("job '%s' completed", j.ID)
-	}
+	j.changed()
+	j.opt.debugf("job '%s' completed", j.ID)
+	j.releaseCheckpoint(err != nil && j.hCtx.Err() != nil)
 	cbk()
+	j.notify()
 }
 
 func (j *job) IsCanceled() bool {
@@ -72,10 +103,51 @@ func (j *job) IsCanceled() bool {
 
 func (j *job) Cancel() {
 	j.cFunc()
+	j.releaseCheckpoint(false)
 	j.mu.Lock()
-	t := time.Now().UTC()
+	t := j.opt.clock.Now().UTC()
 	j.Canceled = &t
+	pending := j.Started == nil
+	if pending {
+		j.endQueueSpan(context.Canceled)
+	}
+	j.mu.Unlock()
+	j.changed()
+	if pending {
+		go j.notify()
+	}
+}
+
+// notify passes the final job state to callbacks and webhooks once the job will no longer change.
+func (j *job) notify() {
+	j.mu.Lock()
+	if j.notified {
+		j.mu.Unlock()
+		return
+	}
+	j.notified = true
+	meta := j.Job
+	callbacks := j.callbacks
+	webhooks := j.webhooks
 	j.mu.Unlock()
+	j.opt.deliver(j.hCtx, meta, callbacks, webhooks)
+}
+
+// changed updates statistics and publishes the current job state to subscribers.
+func (j *job) changed() {
+	if j.stats != nil {
+		j.stats.update(j)
+	}
+	if j.events != nil {
+		j.events.publish(j.Meta())
+	}
+}
+
+// dequeue removes a pending job from the queue of its pool, the handler lock must be held.
+func (j *job) dequeue() {
+	if j.pool != nil {
+		j.pool.queue.remove(j)
+	}
 }
 
 func (j *job) Meta() lib.Job {
@@ -83,3 +155,24 @@ func (j *job) Meta() lib.Job {
 	defer j.mu.RUnlock()
 	return j.Job
 }
+
+func (o *options) newJobErr(err error) *lib.JobErr {
+	var jErr *lib.JobErr
+	if tErr, ok := lib.AsJobErr(err); ok && tErr != nil {
+		jErr = lib.NewJobErr(tErr.Kind, err, tErr.Retriable, tErr.Details)
+		jErr.Code = tErr.Code
+	} else {
+		switch {
+		case errors.Is(err, context.Canceled):
+			jErr = lib.NewJobErr(lib.ErrKindCanceled, err, false, nil)
+		case errors.Is(err, context.DeadlineExceeded):
+			jErr = lib.NewJobErr(lib.ErrKindTimeout, err, true, nil)
+		default:
+			jErr = lib.NewJobErr("", err, false, nil)
+		}
+	}
+	if jErr.Code == nil {
+		jErr.Code = o.errCode(err)
+	}
+	return jErr
+}
@@ -24,3 +24,34 @@ const (
 	JobError     JobStatus = "error"
 	JobOK        JobStatus = "ok"
 )
+
+const (
+	ParamString  ParamType = "string"
+	ParamNumber  ParamType = "number"
+	ParamInteger ParamType = "integer"
+	ParamBool    ParamType = "bool"
+	ParamObject  ParamType = "object"
+	ParamArray   ParamType = "array"
+)
+
+const (
+	ErrKindInternal   ErrKind = "internal"
+	ErrKindValidation ErrKind = "validation"
+	ErrKindNotFound   ErrKind = "not_found"
+	ErrKindTransient  ErrKind = "transient"
+	ErrKindTimeout    ErrKind = "timeout"
+	ErrKindCanceled   ErrKind = "canceled"
+)
+
+const (
+	PriorityLow    JobPriority = -1
+	PriorityNormal JobPriority = 0
+	PriorityHigh   JobPriority = 1
+)
+
+const (
+	SortByCreated   JobSortKey = "created"
+	SortByStarted   JobSortKey = "started"
+	SortByCompleted JobSortKey = "completed"
+	SortByDuration  JobSortKey = "duration"
+)
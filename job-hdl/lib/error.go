@@ -0,0 +1,84 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import "errors"
+
+// NewJobErr wraps err with structured information. Targets can return it to control the error stored on the job.
+// Returns nil if err is nil.
+func NewJobErr(kind ErrKind, err error, retriable bool, details map[string]any) *JobErr {
+	if err == nil {
+		return nil
+	}
+	return &JobErr{
+		Message:   err.Error(),
+		Kind:      kind,
+		Retriable: retriable,
+		Details:   details,
+		Causes:    ErrCauses(err),
+		cause:     err,
+	}
+}
+
+func (e *JobErr) Error() string {
+	return e.Message
+}
+
+func (e *JobErr) Unwrap() error {
+	return e.cause
+}
+
+// ErrCauses returns the messages of all errors wrapped by err, depth first. Messages equal to the
+// preceding one are skipped.
+func ErrCauses(err error) []string {
+	if err == nil {
+		return nil
+	}
+	var causes []string
+	last := err.Error()
+	var walk func(error)
+	walk = func(e error) {
+		var wrapped []error
+		switch u := e.(type) {
+		case interface{ Unwrap() error }:
+			if c := u.Unwrap(); c != nil {
+				wrapped = []error{c}
+			}
+		case interface{ Unwrap() []error }:
+			wrapped = u.Unwrap()
+		}
+		for _, c := range wrapped {
+			if c == nil {
+				continue
+			}
+			if msg := c.Error(); msg != last {
+				causes = append(causes, msg)
+				last = msg
+			}
+			walk(c)
+		}
+	}
+	walk(err)
+	return causes
+}
+
+// AsJobErr returns the first *JobErr in the chain of err.
+func AsJobErr(err error) (*JobErr, bool) {
+	var jErr *JobErr
+	ok := errors.As(err, &jErr)
+	return jErr, ok
+}
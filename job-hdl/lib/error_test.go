@@ -0,0 +1,38 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestNewJobErr(t *testing.T) {
+	if jErr := NewJobErr(ErrKindInternal, nil, false, nil); jErr != nil {
+		t.Errorf("expected nil, got '%v'", jErr)
+	}
+	if causes := ErrCauses(nil); causes != nil {
+		t.Errorf("expected no causes, got '%v'", causes)
+	}
+	base := errors.New("base")
+	jErr := NewJobErr(ErrKindTransient, fmt.Errorf("wrapped: %w", base), true, nil)
+	if !errors.Is(jErr, base) || !reflect.DeepEqual(jErr.Causes, []string{"base"}) {
+		t.Errorf("unexpected job error '%+v'", jErr)
+	}
+}
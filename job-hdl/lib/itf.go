@@ -16,10 +16,34 @@
 
 package lib
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
 
 type Api interface {
 	GetJobs(ctx context.Context, filter JobFilter) ([]Job, error)
 	GetJob(ctx context.Context, jID string) (Job, error)
 	CancelJob(ctx context.Context, jID string) error
 }
+
+// CancelApi cancels all jobs matching a filter.
+type CancelApi interface {
+	CancelJobs(ctx context.Context, filter JobFilter) ([]string, error)
+}
+
+// SubmitApi creates jobs of registered job types.
+type SubmitApi interface {
+	SubmitJob(ctx context.Context, typeName string, params json.RawMessage) (string, error)
+	GetJobTypes(ctx context.Context) ([]JobType, error)
+}
+
+type StatsApi interface {
+	GetJobStats(ctx context.Context, window time.Duration) (JobStats, error)
+}
+
+type BatchApi interface {
+	GetJobBatch(ctx context.Context, bID string) (Batch, error)
+	CancelJobBatch(ctx context.Context, bID string) error
+}
@@ -19,26 +19,126 @@ package lib
 import "time"
 
 type Job struct {
-	ID          string     `json:"id"`
-	Error       *JobErr    `json:"error"`
-	Result      any        `json:"result"`
-	Created     time.Time  `json:"created"`
-	Started     *time.Time `json:"started"`
-	Completed   *time.Time `json:"completed"`
-	Canceled    *time.Time `json:"canceled"`
-	Description string     `json:"description"`
+	ID            string       `json:"id"`
+	Error         *JobErr      `json:"error"`
+	Result        any          `json:"result"`
+	Created       time.Time    `json:"created"`
+	Started       *time.Time   `json:"started"`
+	Completed     *time.Time   `json:"completed"`
+	Canceled      *time.Time   `json:"canceled"`
+	Description   string       `json:"description"`
+	Type          string       `json:"type"`
+	TraceID       string       `json:"trace_id"`
+	Owner         string       `json:"owner"`
+	QueuePosition int          `json:"queue_position,omitempty"`
+	Priority      JobPriority  `json:"priority"`
+	Pool          string       `json:"pool"`
+	ParentID      string       `json:"parent_id,omitempty"`
+	Stuck         *time.Time   `json:"stuck,omitempty"`
+	Abandoned     *time.Time   `json:"abandoned,omitempty"`
+	BatchID       string       `json:"batch_id,omitempty"`
+	Progress      *JobProgress `json:"progress,omitempty"`
 }
 
 type JobErr struct {
-	Message string `json:"message"`
-	Code    *int   `json:"code"`
+	Message   string         `json:"message"`
+	Code      *int           `json:"code"`
+	Kind      ErrKind        `json:"kind,omitempty"`
+	Retriable bool           `json:"retriable,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	Causes    []string       `json:"causes,omitempty"`
+	cause     error
 }
 
+type ErrKind = string
+
 type JobStatus = string
 
+type JobPriority = int
+
 type JobFilter struct {
-	Status   JobStatus
-	SortDesc bool
-	Since    time.Time
-	Until    time.Time
+	Status         JobStatus
+	SortDesc       bool
+	SortBy         JobSortKey
+	Since          time.Time
+	Until          time.Time
+	StartedSince   time.Time
+	StartedUntil   time.Time
+	CompletedSince time.Time
+	CompletedUntil time.Time
+	MinDuration    time.Duration
+	MaxDuration    time.Duration
+	ErrorCode      *int
+	Priority       *JobPriority
+}
+
+type JobSortKey = string
+
+type JobType struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description"`
+	Params      map[string]JobParam `json:"params"`
+	Resumable   bool                `json:"resumable"`
+}
+
+type JobParam struct {
+	Type        ParamType `json:"type"`
+	Required    bool      `json:"required"`
+	Description string    `json:"description"`
+}
+
+type ParamType = string
+
+type PoolInfo struct {
+	Name        string  `json:"name"`
+	Workers     int     `json:"workers"`
+	Active      int     `json:"active"`
+	Pending     int     `json:"pending"`
+	Utilization float64 `json:"utilization"`
+}
+
+type JobEvent struct {
+	ID  string `json:"id"`
+	Job Job    `json:"job"`
+}
+
+type JobStats struct {
+	Counts        map[JobStatus]int `json:"counts"`
+	Window        time.Duration     `json:"window"`
+	Finished      int               `json:"finished"`
+	Failed        int               `json:"failed"`
+	FailureRate   float64           `json:"failure_rate"`
+	AvgRunTime    time.Duration     `json:"avg_run_time"`
+	P95RunTime    time.Duration     `json:"p95_run_time"`
+	AvgQueueTime  time.Duration     `json:"avg_queue_time"`
+	P95QueueTime  time.Duration     `json:"p95_queue_time"`
+	OldestPending *Job              `json:"oldest_pending"`
+	Stuck         int               `json:"stuck"`
+	Abandoned     int               `json:"abandoned"`
+}
+
+type Batch struct {
+	ID      string            `json:"id"`
+	Created time.Time         `json:"created"`
+	Total   int               `json:"total"`
+	Counts  map[JobStatus]int `json:"counts"`
+	Jobs    []Job             `json:"jobs"`
+}
+
+type JobProgress struct {
+	Total     int `json:"total"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+}
+
+type MapResult struct {
+	Items     []MapItem `json:"items"`
+	Succeeded int       `json:"succeeded"`
+	Failed    int       `json:"failed"`
+}
+
+type MapItem struct {
+	Index  int     `json:"index"`
+	Result any     `json:"result,omitempty"`
+	Error  *JobErr `json:"error,omitempty"`
 }
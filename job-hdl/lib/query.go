@@ -0,0 +1,148 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	QueryStatus         = "status"
+	QuerySortDesc       = "sort_desc"
+	QuerySortBy         = "sort_by"
+	QuerySince          = "since"
+	QueryUntil          = "until"
+	QueryStartedSince   = "started_since"
+	QueryStartedUntil   = "started_until"
+	QueryCompletedSince = "completed_since"
+	QueryCompletedUntil = "completed_until"
+	QueryMinDuration    = "min_duration"
+	QueryMaxDuration    = "max_duration"
+	QueryErrorCode      = "error_code"
+	QueryPriority       = "priority"
+)
+
+// Values encodes the filter as URL query parameters, times are formatted as RFC 3339 and durations
+// as accepted by time.ParseDuration.
+func (f JobFilter) Values() url.Values {
+	v := make(url.Values)
+	if f.Status != "" {
+		v.Set(QueryStatus, f.Status)
+	}
+	if f.SortDesc {
+		v.Set(QuerySortDesc, "true")
+	}
+	if f.SortBy != "" {
+		v.Set(QuerySortBy, f.SortBy)
+	}
+	setTime(v, QuerySince, f.Since)
+	setTime(v, QueryUntil, f.Until)
+	setTime(v, QueryStartedSince, f.StartedSince)
+	setTime(v, QueryStartedUntil, f.StartedUntil)
+	setTime(v, QueryCompletedSince, f.CompletedSince)
+	setTime(v, QueryCompletedUntil, f.CompletedUntil)
+	if f.MinDuration > 0 {
+		v.Set(QueryMinDuration, f.MinDuration.String())
+	}
+	if f.MaxDuration > 0 {
+		v.Set(QueryMaxDuration, f.MaxDuration.String())
+	}
+	if f.ErrorCode != nil {
+		v.Set(QueryErrorCode, strconv.Itoa(*f.ErrorCode))
+	}
+	if f.Priority != nil {
+		v.Set(QueryPriority, strconv.Itoa(*f.Priority))
+	}
+	return v
+}
+
+func setTime(v url.Values, key string, t time.Time) {
+	if !t.IsZero() {
+		v.Set(key, t.Format(time.RFC3339Nano))
+	}
+}
+
+// ParseJobFilter decodes a filter from URL query parameters created by JobFilter.Values.
+func ParseJobFilter(v url.Values) (JobFilter, error) {
+	var f JobFilter
+	var err error
+	f.Status = v.Get(QueryStatus)
+	f.SortBy = v.Get(QuerySortBy)
+	if s := v.Get(QuerySortDesc); s != "" {
+		if f.SortDesc, err = strconv.ParseBool(s); err != nil {
+			return JobFilter{}, fmt.Errorf("invalid %s: %s", QuerySortDesc, err)
+		}
+	}
+	if f.Since, err = parseTime(v, QuerySince); err != nil {
+		return JobFilter{}, err
+	}
+	if f.Until, err = parseTime(v, QueryUntil); err != nil {
+		return JobFilter{}, err
+	}
+	if f.StartedSince, err = parseTime(v, QueryStartedSince); err != nil {
+		return JobFilter{}, err
+	}
+	if f.StartedUntil, err = parseTime(v, QueryStartedUntil); err != nil {
+		return JobFilter{}, err
+	}
+	if f.CompletedSince, err = parseTime(v, QueryCompletedSince); err != nil {
+		return JobFilter{}, err
+	}
+	if f.CompletedUntil, err = parseTime(v, QueryCompletedUntil); err != nil {
+		return JobFilter{}, err
+	}
+	if s := v.Get(QueryMinDuration); s != "" {
+		if f.MinDuration, err = time.ParseDuration(s); err != nil {
+			return JobFilter{}, fmt.Errorf("invalid %s: %s", QueryMinDuration, err)
+		}
+	}
+	if s := v.Get(QueryMaxDuration); s != "" {
+		if f.MaxDuration, err = time.ParseDuration(s); err != nil {
+			return JobFilter{}, fmt.Errorf("invalid %s: %s", QueryMaxDuration, err)
+		}
+	}
+	if s := v.Get(QueryErrorCode); s != "" {
+		code, err := strconv.Atoi(s)
+		if err != nil {
+			return JobFilter{}, fmt.Errorf("invalid %s: %s", QueryErrorCode, err)
+		}
+		f.ErrorCode = &code
+	}
+	if s := v.Get(QueryPriority); s != "" {
+		p, err := strconv.Atoi(s)
+		if err != nil {
+			return JobFilter{}, fmt.Errorf("invalid %s: %s", QueryPriority, err)
+		}
+		f.Priority = &p
+	}
+	return f, nil
+}
+
+func parseTime(v url.Values, key string) (time.Time, error) {
+	s := v.Get(key)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %s", key, err)
+	}
+	return t, nil
+}
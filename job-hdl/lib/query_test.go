@@ -0,0 +1,59 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJobFilterValues(t *testing.T) {
+	code := 500
+	priority := PriorityHigh
+	tm := time.Date(2024, 1, 1, 12, 30, 0, 500, time.UTC)
+	filter := JobFilter{
+		Status:         JobError,
+		SortDesc:       true,
+		SortBy:         SortByDuration,
+		Since:          tm,
+		Until:          tm.Add(time.Hour),
+		StartedSince:   tm.Add(time.Minute),
+		StartedUntil:   tm.Add(2 * time.Minute),
+		CompletedSince: tm.Add(3 * time.Minute),
+		CompletedUntil: tm.Add(4 * time.Minute),
+		MinDuration:    5 * time.Minute,
+		MaxDuration:    90 * time.Second,
+		ErrorCode:      &code,
+		Priority:       &priority,
+	}
+	parsed, err := ParseJobFilter(filter.Values())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, filter) {
+		t.Errorf("parsed filter is '%+v', expected '%+v'", parsed, filter)
+	}
+	invalid := url.Values{QuerySince: {"x"}, QueryMinDuration: {"5"}, QueryErrorCode: {"x"}}
+	for i := 0; i < 10; i++ {
+		if _, err = ParseJobFilter(invalid); err == nil || !strings.HasPrefix(err.Error(), "invalid "+QuerySince) {
+			t.Fatalf("expected error for '%s', got '%v'", QuerySince, err)
+		}
+	}
+}
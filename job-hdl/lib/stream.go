@@ -0,0 +1,176 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	QueryJobID        = "id"
+	LastEventIDHeader = "Last-Event-ID"
+	JobEventName      = "job"
+)
+
+// StreamClient consumes a job event stream served as Server-Sent Events. Connections are
+// re-established after errors and resume with the ID of the last received event.
+type StreamClient struct {
+	// URL of the stream endpoint.
+	URL string
+	// HttpClient used for requests, defaults to http.DefaultClient. Should not set a timeout.
+	HttpClient *http.Client
+	// RetryDelay between reconnects, defaults to 1s. Overridden by the retry field sent by the server.
+	RetryDelay time.Duration
+	// MaxFailures limits consecutive failed connection attempts, zero means no limit.
+	MaxFailures int
+	// LastEventID to resume from on the first connection.
+	LastEventID string
+}
+
+// Stream delivers events of jobs matching the filter and, if not empty, the job ID to hdl until
+// the context is done, hdl returns an error or MaxFailures is exceeded.
+func (c *StreamClient) Stream(ctx context.Context, filter JobFilter, jID string, hdl func(JobEvent) error) error {
+	client := c.HttpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	delay := c.RetryDelay
+	if delay <= 0 {
+		delay = time.Second
+	}
+	query := filter.Values()
+	if jID != "" {
+		query.Set(QueryJobID, jID)
+	}
+	u := c.URL
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	lastID := c.LastEventID
+	failures := 0
+	for {
+		received, retry, err := c.connect(ctx, client, u, &lastID, hdl)
+		var hErr *handlerErr
+		if errors.As(err, &hErr) {
+			return hErr.err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if retry > 0 {
+			delay = retry
+		}
+		if received {
+			failures = 0
+		} else if err != nil {
+			failures++
+			if c.MaxFailures > 0 && failures >= c.MaxFailures {
+				return err
+			}
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type handlerErr struct {
+	err error
+}
+
+func (e *handlerErr) Error() string {
+	return e.err.Error()
+}
+
+func (c *StreamClient) connect(ctx context.Context, client *http.Client, u string, lastID *string, hdl func(JobEvent) error) (bool, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if *lastID != "" {
+		req.Header.Set(LastEventIDHeader, *lastID)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, 0, fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	var retry time.Duration
+	var received bool
+	var id, event string
+	var data []string
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if len(data) > 0 && (event == "" || event == JobEventName) {
+				var e JobEvent
+				if err = json.Unmarshal([]byte(strings.Join(data, "\n")), &e.Job); err != nil {
+					return received, retry, err
+				}
+				e.ID = id
+				if id != "" {
+					*lastID = id
+				}
+				received = true
+				if err = hdl(e); err != nil {
+					return received, retry, &handlerErr{err: err}
+				}
+			}
+			id, event, data = "", "", nil
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return received, retry, err
+	}
+	return received, retry, errors.New("stream closed")
+}
@@ -18,38 +18,216 @@ package lib
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net"
+	"reflect"
+	"syscall"
 	"time"
 )
 
-func Await(ctx context.Context, client Api, jID string, delay, httpTimeout time.Duration, logger interface{ Error(arg ...any) }) (Job, error) {
-	ticker := time.NewTicker(delay)
-	defer ticker.Stop()
-	var cfs []context.CancelFunc
-	defer func() {
-		for _, cf := range cfs {
-			cf()
+type AwaitOptions struct {
+	// Delay is the initial polling delay, defaults to 500ms.
+	Delay time.Duration
+	// MaxDelay caps the polling delay, defaults to 10s.
+	MaxDelay time.Duration
+	// Multiplier is applied to the delay after each poll, defaults to 1.5.
+	Multiplier float64
+	// HttpTimeout limits each request, defaults to 10s.
+	HttpTimeout time.Duration
+	// MaxFailures is the number of consecutive transient errors tolerated, defaults to 3. Negative values disable tolerance.
+	MaxFailures int
+	// IsTransient classifies errors, defaults to IsTransientErr.
+	IsTransient func(error) bool
+	// CancelPolicy decides which jobs are canceled if the context is done, defaults to CancelPending.
+	CancelPolicy CancelPolicy
+	// OnUpdate is called with each fetched job that differs from the previously fetched state.
+	OnUpdate func(Job)
+	Logger   interface{ Error(arg ...any) }
+}
+
+type CancelPolicy int
+
+const (
+	// CancelPending cancels all awaited jobs that are not completed yet.
+	CancelPending CancelPolicy = iota
+	// CancelNone leaves all jobs running.
+	CancelNone
+)
+
+type AwaitResult struct {
+	ID  string
+	Job Job
+	Err error
+}
+
+func (o AwaitOptions) withDefaults() AwaitOptions {
+	if o.Delay <= 0 {
+		o.Delay = 500 * time.Millisecond
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 10 * time.Second
+	}
+	if o.MaxDelay < o.Delay {
+		o.MaxDelay = o.Delay
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	if o.HttpTimeout <= 0 {
+		o.HttpTimeout = 10 * time.Second
+	}
+	if o.MaxFailures == 0 {
+		o.MaxFailures = 3
+	} else if o.MaxFailures < 0 {
+		o.MaxFailures = 0
+	}
+	if o.IsTransient == nil {
+		o.IsTransient = IsTransientErr
+	}
+	return o
+}
+
+func (o AwaitOptions) nextDelay(d time.Duration) time.Duration {
+	d = time.Duration(float64(d) * o.Multiplier)
+	if d > o.MaxDelay {
+		return o.MaxDelay
+	}
+	return d
+}
+
+// Await polls a job until it is completed. Transient errors are tolerated up to opt.MaxFailures
+// consecutive times, on cancellation of ctx the job is canceled according to opt.CancelPolicy.
+func Await(ctx context.Context, client Api, jID string, opt AwaitOptions) (Job, error) {
+	results, _, err := awaitJobs(ctx, client, []string{jID}, opt, false)
+	if err != nil {
+		return Job{}, err
+	}
+	if results[0].Err != nil {
+		return Job{}, results[0].Err
+	}
+	return results[0].Job, nil
+}
+
+// AwaitAll polls jobs until all are completed or failed to be retrieved. Results are in the order
+// of jIDs. An error is only returned if ctx is done, in which case results are partial.
+func AwaitAll(ctx context.Context, client Api, jIDs []string, opt AwaitOptions) ([]AwaitResult, error) {
+	results, _, err := awaitJobs(ctx, client, jIDs, opt, false)
+	return results, err
+}
+
+// AwaitAny polls jobs until the first one is completed. Remaining jobs are not canceled unless ctx is done.
+func AwaitAny(ctx context.Context, client Api, jIDs []string, opt AwaitOptions) (AwaitResult, error) {
+	if len(jIDs) == 0 {
+		return AwaitResult{}, errors.New("no jobs")
+	}
+	results, i, err := awaitJobs(ctx, client, jIDs, opt, true)
+	if err != nil {
+		return AwaitResult{}, err
+	}
+	if i < 0 {
+		var errs []error
+		for _, r := range results {
+			errs = append(errs, r.Err)
 		}
-	}()
-	for {
+		return AwaitResult{}, errors.Join(errs...)
+	}
+	return results[i], nil
+}
+
+// awaitJobs polls all open jobs per tick. If anyDone is true it returns after the first completed job
+// and its index, otherwise the index is -1.
+func awaitJobs(ctx context.Context, client Api, jIDs []string, opt AwaitOptions, anyDone bool) ([]AwaitResult, int, error) {
+	opt = opt.withDefaults()
+	results := make([]AwaitResult, len(jIDs))
+	for i, id := range jIDs {
+		results[i].ID = id
+	}
+	failures := make([]int, len(jIDs))
+	done := make([]bool, len(jIDs))
+	open := len(jIDs)
+	delay := opt.Delay
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for open > 0 {
 		select {
 		case <-ctx.Done():
-			c, cf := context.WithTimeout(context.Background(), httpTimeout)
-			err := client.CancelJob(c, jID)
-			if err != nil && logger != nil {
-				logger.Error(err)
-			}
-			cf()
-			return Job{}, ctx.Err()
-		case <-ticker.C:
-			c, cf := context.WithTimeout(context.Background(), httpTimeout)
-			cfs = append(cfs, cf)
-			j, err := client.GetJob(c, jID)
-			if err != nil {
-				return Job{}, err
+			if opt.CancelPolicy == CancelPending {
+				for i, id := range jIDs {
+					if !done[i] {
+						c, cf := context.WithTimeout(context.Background(), opt.HttpTimeout)
+						err := client.CancelJob(c, id)
+						if err != nil && opt.Logger != nil {
+							opt.Logger.Error(err)
+						}
+						cf()
+					}
+				}
 			}
-			if j.Completed != nil {
-				return j, nil
+			return results, -1, ctx.Err()
+		case <-timer.C:
+			for i, id := range jIDs {
+				if done[i] {
+					continue
+				}
+				c, cf := context.WithTimeout(context.Background(), opt.HttpTimeout)
+				j, err := client.GetJob(c, id)
+				cf()
+				if err != nil {
+					if !opt.IsTransient(err) || failures[i] >= opt.MaxFailures {
+						results[i].Err = err
+						done[i] = true
+						open--
+						continue
+					}
+					failures[i]++
+					if opt.Logger != nil {
+						opt.Logger.Error(err)
+					}
+					continue
+				}
+				failures[i] = 0
+				if opt.OnUpdate != nil && !reflect.DeepEqual(results[i].Job, j) {
+					opt.OnUpdate(j)
+				}
+				results[i].Job = j
+				if j.Completed != nil {
+					done[i] = true
+					open--
+					if anyDone {
+						return results, i, nil
+					}
+				}
 			}
+			delay = opt.nextDelay(delay)
+			timer.Reset(delay)
 		}
 	}
+	return results, -1, nil
+}
+
+// IsTransientErr reports whether err is likely temporary, like timeouts and refused or reset connections.
+func IsTransientErr(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	var tmpErr interface{ Temporary() bool }
+	if errors.As(err, &tmpErr) {
+		return tmpErr.Temporary()
+	}
+	return false
 }
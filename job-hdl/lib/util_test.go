@@ -0,0 +1,176 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+type testApi struct {
+	Api
+	mu       sync.Mutex
+	errs     []error
+	polls    int
+	canceled bool
+}
+
+func (a *testApi) GetJob(_ context.Context, jID string) (Job, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.polls++
+	if len(a.errs) > 0 {
+		err := a.errs[0]
+		a.errs = a.errs[1:]
+		if err != nil {
+			return Job{}, err
+		}
+	}
+	j := Job{ID: jID}
+	if len(a.errs) == 0 {
+		t := time.Now()
+		j.Completed = &t
+	}
+	return j, nil
+}
+
+func (a *testApi) CancelJob(_ context.Context, _ string) error {
+	a.mu.Lock()
+	a.canceled = true
+	a.mu.Unlock()
+	return nil
+}
+
+func TestAwait(t *testing.T) {
+	opt := AwaitOptions{Delay: time.Millisecond, MaxDelay: 5 * time.Millisecond, MaxFailures: 2}
+	api := &testApi{errs: []error{syscall.ECONNREFUSED, nil, context.DeadlineExceeded, syscall.ECONNRESET, nil}}
+	j, err := Await(context.Background(), api, "test", opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if j.Completed == nil {
+		t.Error("job not completed")
+	}
+	if api.polls != 5 {
+		t.Errorf("polled '%d' times, expected '5'", api.polls)
+	}
+	api = &testApi{errs: []error{syscall.ECONNREFUSED, syscall.ECONNREFUSED, syscall.ECONNREFUSED, nil}}
+	if _, err = Await(context.Background(), api, "test", opt); !errors.Is(err, syscall.ECONNREFUSED) {
+		t.Errorf("error is '%v', expected '%v'", err, syscall.ECONNREFUSED)
+	}
+	testErr := errors.New("not found")
+	api = &testApi{errs: []error{testErr, nil}}
+	if _, err = Await(context.Background(), api, "test", opt); !errors.Is(err, testErr) {
+		t.Errorf("error is '%v', expected '%v'", err, testErr)
+	}
+	if api.polls != 1 {
+		t.Errorf("polled '%d' times, expected '1'", api.polls)
+	}
+}
+
+func TestAwaitCancel(t *testing.T) {
+	api := &testApi{errs: make([]error, 1000)}
+	ctx, cf := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cf()
+	if _, err := Await(ctx, api, "test", AwaitOptions{Delay: time.Millisecond}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error is '%v', expected '%v'", err, context.DeadlineExceeded)
+	}
+	if !api.canceled {
+		t.Error("job not canceled")
+	}
+}
+
+type testMultiApi struct {
+	Api
+	mu       sync.Mutex
+	polls    map[string]int
+	canceled map[string]bool
+}
+
+// GetJob completes a job after as many polls as its ID has characters, ID "x" is never completed.
+func (a *testMultiApi) GetJob(_ context.Context, jID string) (Job, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if jID == "" {
+		return Job{}, errors.New("not found")
+	}
+	a.polls[jID]++
+	j := Job{ID: jID}
+	if jID != "x" && a.polls[jID] >= len(jID) {
+		t := time.Now()
+		j.Completed = &t
+	}
+	return j, nil
+}
+
+func (a *testMultiApi) CancelJob(_ context.Context, jID string) error {
+	a.mu.Lock()
+	a.canceled[jID] = true
+	a.mu.Unlock()
+	return nil
+}
+
+func TestAwaitMultiple(t *testing.T) {
+	opt := AwaitOptions{Delay: time.Millisecond}
+	api := &testMultiApi{polls: make(map[string]int), canceled: make(map[string]bool)}
+	results, err := AwaitAll(context.Background(), api, []string{"aaa", "", "a"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Job.Completed == nil || results[2].Job.Completed == nil {
+		t.Error("jobs not completed")
+	}
+	if results[1].Err == nil {
+		t.Error("missing error for unknown job")
+	}
+	r, err := AwaitAny(context.Background(), api, []string{"bbb", "bb"}, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.ID != "bb" {
+		t.Errorf("first job is '%s', expected 'bb'", r.ID)
+	}
+	ctx, cf := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cf()
+	if _, err = AwaitAll(ctx, api, []string{"c", "x"}, opt); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error is '%v', expected '%v'", err, context.DeadlineExceeded)
+	}
+	if api.canceled["c"] || !api.canceled["x"] {
+		t.Errorf("canceled jobs are '%v', expected only 'x'", api.canceled)
+	}
+}
+
+func TestAwaitOnUpdate(t *testing.T) {
+	var updates []Job
+	api := &testApi{errs: []error{nil, nil, nil}}
+	_, err := Await(context.Background(), api, "test", AwaitOptions{Delay: time.Millisecond, OnUpdate: func(j Job) {
+		updates = append(updates, j)
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 {
+		t.Fatalf("got '%d' updates, expected '2'", len(updates))
+	}
+	if updates[0].Completed != nil || updates[1].Completed == nil {
+		t.Error("updates not in order")
+	}
+}
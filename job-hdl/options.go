@@ -0,0 +1,365 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"net/http"
+	"time"
+)
+
+const DefaultPriorityAging = time.Minute
+
+type options struct {
+	logger          Log
+	errCodeMapper   func(error) *int
+	newInternalErr  func(error) error
+	newNotFoundErr  func(error) error
+	newInvalidInErr func(error) error
+	newQueueFullErr func(error) error
+	maxPending      int
+	maxPendingOwner int
+	maxPendingType  int
+	priorityAging   time.Duration
+	pools           []poolConfig
+	checkpoints     CheckpointStore
+	eventBuffer     int
+	statsRetention  time.Duration
+	cancelGrace     time.Duration
+	maxRuntime      time.Duration
+	maxAbandoned    int
+	stuckInterval   time.Duration
+	clock           Clock
+	webhookSecret   []byte
+	webhookClient   *http.Client
+	webhookRetries  int
+	webhookDelay    time.Duration
+	tracer          Tracer
+}
+
+type Option func(*options)
+
+// WithLogger sets the logger of a handler, defaults to the package level Logger.
+func WithLogger(l Log) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithErrCodeMapper sets the function providing job error codes, defaults to the package level ErrCodeMapper.
+func WithErrCodeMapper(f func(error) *int) Option {
+	return func(o *options) {
+		o.errCodeMapper = f
+	}
+}
+
+// WithInternalErr sets the function wrapping internal errors, defaults to the package level NewInternalErr.
+func WithInternalErr(f func(error) error) Option {
+	return func(o *options) {
+		o.newInternalErr = f
+	}
+}
+
+// WithNotFoundErr sets the function wrapping not found errors, defaults to the package level NewNotFoundErr.
+func WithNotFoundErr(f func(error) error) Option {
+	return func(o *options) {
+		o.newNotFoundErr = f
+	}
+}
+
+// WithInvalidInputErr sets the function wrapping invalid input errors, defaults to the package level NewInvalidInputError.
+func WithInvalidInputErr(f func(error) error) Option {
+	return func(o *options) {
+		o.newInvalidInErr = f
+	}
+}
+
+// WithQueueFullErr sets the function wrapping errors caused by exceeded queue limits, defaults to the package level NewQueueFullErr.
+func WithQueueFullErr(f func(error) error) Option {
+	return func(o *options) {
+		o.newQueueFullErr = f
+	}
+}
+
+// WithMaxPending limits the number of pending jobs overall, per owner and per job type. Values <= 0 disable a limit.
+func WithMaxPending(total, perOwner, perType int) Option {
+	return func(o *options) {
+		o.maxPending = total
+		o.maxPendingOwner = perOwner
+		o.maxPendingType = perType
+	}
+}
+
+// WithPriorityAging sets the waiting time after which the priority of a pending job is raised by
+// one, defaults to DefaultPriorityAging. Values <= 0 disable aging.
+func WithPriorityAging(d time.Duration) Option {
+	return func(o *options) {
+		o.priorityAging = d
+	}
+}
+
+// WithPool adds a named pool of workers backed by its own executor. Jobs are assigned to a pool
+// with UsePool. The pool DefaultPool uses the executor passed to New unless configured here.
+// The number of workers is only used to report utilization and should match the executor.
+func WithPool(name string, exec Executor, workers int) Option {
+	return func(o *options) {
+		o.pools = append(o.pools, poolConfig{name: name, exec: exec, workers: workers})
+	}
+}
+
+// WithCheckpointStore enables checkpointing and resuming of jobs of resumable job types.
+func WithCheckpointStore(s CheckpointStore) Option {
+	return func(o *options) {
+		o.checkpoints = s
+	}
+}
+
+// WithEventBuffer sets the number of recent job events kept for resuming subscriptions, defaults to DefaultEventBuffer.
+func WithEventBuffer(n int) Option {
+	return func(o *options) {
+		o.eventBuffer = n
+	}
+}
+
+// WithStatsRetention sets how long duration samples are kept for statistics, defaults to DefaultStatsRetention.
+func WithStatsRetention(d time.Duration) Option {
+	return func(o *options) {
+		o.statsRetention = d
+	}
+}
+
+// WithCancelGracePeriod sets how long a canceled job may keep running before it is flagged as
+// abandoned, defaults to DefaultCancelGracePeriod. Zero disables the check.
+func WithCancelGracePeriod(d time.Duration) Option {
+	return func(o *options) {
+		o.cancelGrace = d
+	}
+}
+
+// WithMaxRuntime sets how long a job may run before it is flagged as stuck. Zero, the default,
+// disables the check.
+func WithMaxRuntime(d time.Duration) Option {
+	return func(o *options) {
+		o.maxRuntime = d
+	}
+}
+
+// WithStuckCheckInterval sets the interval of the background check for stuck and abandoned jobs,
+// defaults to DefaultStuckCheckInterval. Zero disables the background check.
+func WithStuckCheckInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.stuckInterval = d
+	}
+}
+
+// WithMaxAbandoned sets the number of abandoned jobs at which Handler.IsHealthy reports false,
+// defaults to DefaultMaxAbandoned. Zero disables the health check.
+func WithMaxAbandoned(n int) Option {
+	return func(o *options) {
+		o.maxAbandoned = n
+	}
+}
+
+// WithClock sets the clock used for job timestamps, defaults to the system clock.
+func WithClock(c Clock) Option {
+	return func(o *options) {
+		o.clock = c
+	}
+}
+
+// WithWebhookSecret sets the key used to sign webhook payloads, see SignatureHeader.
+func WithWebhookSecret(secret []byte) Option {
+	return func(o *options) {
+		o.webhookSecret = secret
+	}
+}
+
+func WithWebhookClient(c *http.Client) Option {
+	return func(o *options) {
+		o.webhookClient = c
+	}
+}
+
+// WithWebhookRetries sets how often a failed webhook delivery is retried. The delay doubles after each attempt.
+func WithWebhookRetries(retries int, delay time.Duration) Option {
+	return func(o *options) {
+		o.webhookRetries = retries
+		o.webhookDelay = delay
+	}
+}
+
+// WithTracer enables tracing of job queueing and execution.
+func WithTracer(t Tracer) Option {
+	return func(o *options) {
+		o.tracer = t
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		clock:          systemClock{},
+		priorityAging:  DefaultPriorityAging,
+		eventBuffer:    DefaultEventBuffer,
+		statsRetention: DefaultStatsRetention,
+		cancelGrace:    DefaultCancelGracePeriod,
+		maxAbandoned:   DefaultMaxAbandoned,
+		stuckInterval:  DefaultStuckCheckInterval,
+		webhookClient:  &http.Client{Timeout: 10 * time.Second},
+		webhookRetries: 3,
+		webhookDelay:   time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func (o *options) log() Log {
+	if o.logger != nil {
+		return o.logger
+	}
+	return Logger
+}
+
+func (o *options) errorf(format string, arg ...any) {
+	if l := o.log(); l != nil {
+		l.Errorf(format, arg...)
+	}
+}
+
+func (o *options) warningf(format string, arg ...any) {
+	if l := o.log(); l != nil {
+		l.Warningf(format, arg...)
+	}
+}
+
+func (o *options) debugf(format string, arg ...any) {
+	if l := o.log(); l != nil {
+		l.Debugf(format, arg...)
+	}
+}
+
+func (o *options) errCode(err error) *int {
+	if o.errCodeMapper != nil {
+		return o.errCodeMapper(err)
+	}
+	if ErrCodeMapper != nil {
+		return ErrCodeMapper(err)
+	}
+	return nil
+}
+
+func wrapErr(err error, f, fallback func(error) error) error {
+	if f != nil {
+		return f(err)
+	}
+	if fallback != nil {
+		return fallback(err)
+	}
+	return err
+}
+
+func (o *options) internalErr(err error) error {
+	return wrapErr(err, o.newInternalErr, NewInternalErr)
+}
+
+func (o *options) notFoundErr(err error) error {
+	return wrapErr(err, o.newNotFoundErr, NewNotFoundErr)
+}
+
+func (o *options) invalidInputErr(err error) error {
+	return wrapErr(err, o.newInvalidInErr, NewInvalidInputError)
+}
+
+func (o *options) queueFullErr(err error) error {
+	return wrapErr(err, o.newQueueFullErr, NewQueueFullErr)
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+type createOptions struct {
+	callbacks  []func(lib.Job)
+	webhooks   []string
+	id         string
+	batchID    string
+	created    time.Time
+	cacheKey   string
+	cacheTTL   time.Duration
+	owner      string
+	priority   lib.JobPriority
+	pool       string
+	checkpoint *CheckpointRecord
+}
+
+type CreateOption func(*createOptions)
+
+// WithCallback registers a function that receives the final job once it completed or was canceled before starting.
+func WithCallback(f func(lib.Job)) CreateOption {
+	return func(o *createOptions) {
+		o.callbacks = append(o.callbacks, f)
+	}
+}
+
+// WithWebhook adds a URL the final job is posted to as JSON.
+func WithWebhook(url string) CreateOption {
+	return func(o *createOptions) {
+		o.webhooks = append(o.webhooks, url)
+	}
+}
+
+// WithOwner sets the owner of a job, used for per owner queue limits.
+func WithOwner(owner string) CreateOption {
+	return func(o *createOptions) {
+		o.owner = owner
+	}
+}
+
+// WithPriority sets the priority of a job, pending jobs with higher priority are dispatched first.
+func WithPriority(p lib.JobPriority) CreateOption {
+	return func(o *createOptions) {
+		o.priority = p
+	}
+}
+
+// UsePool sets the pool a job is executed by, defaults to DefaultPool.
+func UsePool(name string) CreateOption {
+	return func(o *createOptions) {
+		o.pool = name
+	}
+}
+
+// WithCacheKey deduplicates jobs doing identical work. Creating a job with the key of a pending or
+// running job returns the ID of that job instead, as does a key of a job completed without error
+// less than ttl ago. Callbacks and webhooks are added to the existing job.
+func WithCacheKey(key string, ttl time.Duration) CreateOption {
+	return func(o *createOptions) {
+		o.cacheKey = key
+		o.cacheTTL = ttl
+	}
+}
+
+func newCreateOptions(opts []CreateOption) createOptions {
+	var o createOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"sync"
+)
+
+// ParallelMap calls f for each item with at most limit calls running at once, limit values below
+// one allow a single call. Items are no longer started once the context is canceled and are
+// recorded as failed with the context error. If the context belongs to a job, the job's progress is
+// updated after each item. Items of the result are in input order.
+func ParallelMap[T, R any](ctx context.Context, items []T, limit int, f func(ctx context.Context, item T) (R, error)) lib.MapResult {
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > len(items) {
+		limit = len(items)
+	}
+	j, _ := jobFromContext(ctx)
+	res := lib.MapResult{Items: make([]lib.MapItem, len(items))}
+	progress := lib.JobProgress{Total: len(items)}
+	j.setProgress(progress)
+	var mu sync.Mutex
+	record := func(i int, r any, err error) {
+		item := lib.MapItem{Index: i}
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			item.Error = newItemErr(j, err)
+			res.Failed++
+			progress.Failed++
+		} else {
+			item.Result = r
+			res.Succeeded++
+			progress.Succeeded++
+		}
+		res.Items[i] = item
+		j.setProgress(progress)
+	}
+	idx := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < limit; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range idx {
+				if err := ctx.Err(); err != nil {
+					record(i, nil, err)
+					continue
+				}
+				r, err := f(ctx, items[i])
+				record(i, r, err)
+			}
+		}()
+	}
+	next := 0
+loop:
+	for ; next < len(items); next++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		default:
+		}
+		select {
+		case idx <- next:
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	close(idx)
+	wg.Wait()
+	for i := next; i < len(items); i++ {
+		record(i, nil, ctx.Err())
+	}
+	return res
+}
+
+func newItemErr(j *job, err error) *lib.JobErr {
+	if j != nil {
+		return j.opt.newJobErr(err)
+	}
+	var o options
+	return o.newJobErr(err)
+}
+
+func (j *job) setProgress(p lib.JobProgress) {
+	if j == nil {
+		return
+	}
+	j.mu.Lock()
+	j.Progress = &p
+	j.mu.Unlock()
+	j.changed()
+}
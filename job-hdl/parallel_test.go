@@ -0,0 +1,83 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"context"
+	"errors"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"sync/atomic"
+	"testing"
+)
+
+func TestParallelMap(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	var running, maxRunning atomic.Int32
+	id, err := hdl.Create(ctx, "map", func(ctx context.Context, _ context.CancelFunc) (any, error) {
+		return job_hdl.ParallelMap(ctx, []int{1, 2, 3, 4, 5, 6}, 2, func(_ context.Context, i int) (int, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			if i == 3 {
+				return 0, errors.New("test error")
+			}
+			return i * 2, nil
+		}), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	j := jhtest.GetJob(t, hdl, id)
+	res, ok := j.Result.(lib.MapResult)
+	if !ok {
+		t.Fatalf("result is '%T', expected lib.MapResult", j.Result)
+	}
+	if res.Succeeded != 5 || res.Failed != 1 || res.Items[1].Result != 4 || res.Items[2].Error == nil || res.Items[2].Error.Message != "test error" {
+		t.Errorf("unexpected result '%+v'", res)
+	}
+	if j.Progress == nil || *j.Progress != (lib.JobProgress{Total: 6, Succeeded: 5, Failed: 1}) {
+		t.Errorf("progress is '%+v'", j.Progress)
+	}
+	if maxRunning.Load() > 2 {
+		t.Errorf("'%d' items run at once, expected at most '2'", maxRunning.Load())
+	}
+}
+
+func TestParallelMapCancel(t *testing.T) {
+	ctx, cf := context.WithCancel(context.Background())
+	res := job_hdl.ParallelMap(ctx, []string{"a", "b", "c"}, 1, func(_ context.Context, s string) (string, error) {
+		cf()
+		return s, nil
+	})
+	if res.Succeeded != 1 || res.Failed != 2 || res.Items[0].Result != "a" {
+		t.Errorf("unexpected result '%+v'", res)
+	}
+	for _, item := range res.Items[1:] {
+		if item.Error == nil || item.Error.Kind != lib.ErrKindCanceled {
+			t.Errorf("item %d has error '%v', expected cancellation", item.Index, item.Error)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"sort"
+)
+
+const DefaultPool = "default"
+
+type poolConfig struct {
+	name    string
+	exec    Executor
+	workers int
+}
+
+// pool fields other than the configuration are guarded by the handler lock.
+type pool struct {
+	poolConfig
+	queue   jobQueue
+	running map[*job]struct{}
+}
+
+func newPools(defaultExec Executor, configs []poolConfig, opt *options) map[string]*pool {
+	pools := map[string]*pool{
+		DefaultPool: {poolConfig: poolConfig{name: DefaultPool, exec: defaultExec}},
+	}
+	for _, c := range configs {
+		pools[c.name] = &pool{poolConfig: c}
+	}
+	for _, p := range pools {
+		p.queue.opt = opt
+		p.running = make(map[*job]struct{})
+	}
+	return pools
+}
+
+// Pools reports the utilization of all pools. Utilization is the ratio of running jobs to workers
+// and 0 for pools configured without a number of workers.
+func (h *Handler) Pools(_ context.Context) ([]lib.PoolInfo, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	infos := make([]lib.PoolInfo, 0, len(h.pools))
+	for _, p := range h.pools {
+		info := lib.PoolInfo{
+			Name:    p.name,
+			Workers: p.workers,
+			Active:  len(p.running),
+			Pending: p.queue.len(),
+		}
+		if p.workers > 0 {
+			info.Utilization = float64(len(p.running)) / float64(p.workers)
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos, nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrQueueFull = errors.New("queue full")
+
+// jobQueue holds pending jobs in creation order, access is guarded by the handler lock. Jobs are
+// dispatched by effective priority, which increases with waiting time if aging is enabled.
+type jobQueue struct {
+	jobs []*job
+	opt  *options
+}
+
+func (q *jobQueue) push(j *job) {
+	q.jobs = append(q.jobs, j)
+}
+
+func (q *jobQueue) pop() *job {
+	if len(q.jobs) == 0 {
+		return nil
+	}
+	now := q.opt.clock.Now()
+	n := 0
+	for i := 1; i < len(q.jobs); i++ {
+		if q.effPriority(q.jobs[i], now) > q.effPriority(q.jobs[n], now) {
+			n = i
+		}
+	}
+	j := q.jobs[n]
+	q.remove(j)
+	return j
+}
+
+func (q *jobQueue) remove(j *job) bool {
+	for i, qj := range q.jobs {
+		if qj == j {
+			copy(q.jobs[i:], q.jobs[i+1:])
+			q.jobs[len(q.jobs)-1] = nil
+			q.jobs = q.jobs[:len(q.jobs)-1]
+			return true
+		}
+	}
+	return false
+}
+
+func (q *jobQueue) len() int {
+	return len(q.jobs)
+}
+
+// position returns the 1-based queue position of a job or 0 if it is not pending.
+func (q *jobQueue) position(j *job) int {
+	return q.positions()[j.ID]
+}
+
+// positions returns the 1-based queue position of each pending job by ID.
+func (q *jobQueue) positions() map[string]int {
+	now := q.opt.clock.Now()
+	ordered := make([]*job, len(q.jobs))
+	copy(ordered, q.jobs)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return q.effPriority(ordered[i], now) > q.effPriority(ordered[j], now)
+	})
+	p := make(map[string]int, len(ordered))
+	for i, j := range ordered {
+		p[j.ID] = i + 1
+	}
+	return p
+}
+
+func (q *jobQueue) effPriority(j *job, now time.Time) int {
+	if q.opt.priorityAging <= 0 {
+		return j.Priority
+	}
+	return j.Priority + int(now.Sub(j.Created)/q.opt.priorityAging)
+}
+
+// checkLimits must be called with the handler lock held.
+func (h *Handler) checkLimits(owner, jType string) error {
+	pending := 0
+	for _, p := range h.pools {
+		pending += p.queue.len()
+	}
+	if h.opt.maxPending > 0 && pending >= h.opt.maxPending {
+		return fmt.Errorf("%w: %d pending jobs", ErrQueueFull, pending)
+	}
+	if h.opt.maxPendingOwner <= 0 && h.opt.maxPendingType <= 0 {
+		return nil
+	}
+	var nOwner, nType int
+	for _, p := range h.pools {
+		for _, j := range p.queue.jobs {
+			if owner != "" && j.Owner == owner {
+				nOwner++
+			}
+			if jType != "" && j.Type == jType {
+				nType++
+			}
+		}
+	}
+	if h.opt.maxPendingOwner > 0 && nOwner >= h.opt.maxPendingOwner {
+		return fmt.Errorf("%w: %d pending jobs of owner '%s'", ErrQueueFull, nOwner, owner)
+	}
+	if h.opt.maxPendingType > 0 && nType >= h.opt.maxPendingType {
+		return fmt.Errorf("%w: %d pending jobs of type '%s'", ErrQueueFull, nType, jType)
+	}
+	return nil
+}
+
+// next removes and returns the next pending job of a pool that is not canceled.
+func (h *Handler) next(p *pool) *job {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for {
+		j := p.queue.pop()
+		if j == nil {
+			return nil
+		}
+		if !j.IsCanceled() {
+			p.running[j] = struct{}{}
+			return j
+		}
+	}
+}
+
+func (h *Handler) done(p *pool, j *job) {
+	h.mu.Lock()
+	delete(p.running, j)
+	h.mu.Unlock()
+}
+
+// dispatcher is passed to the executor of a pool for each queued job and runs whichever pending job
+// is next once the executor calls it, so the handler controls the dispatch order.
+type dispatcher struct {
+	h *Handler
+	p *pool
+}
+
+func (d *dispatcher) CallTarget(cbk func()) {
+	j := d.h.next(d.p)
+	if j == nil {
+		cbk()
+		return
+	}
+	j.CallTarget(func() {
+		d.h.done(d.p, j)
+		cbk()
+	})
+}
+
+// IsCanceled reports true if no job of the pool is pending, which lets the executor drop surplus
+// dispatchers left by jobs canceled while pending.
+func (d *dispatcher) IsCanceled() bool {
+	d.h.mu.RLock()
+	defer d.h.mu.RUnlock()
+	return d.p.queue.len() == 0
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"math"
+	"sort"
+)
+
+// JobType describes a named job that can be started remotely via Submit. Factory receives the
+// validated JSON parameters and returns the target to run.
+type JobType struct {
+	lib.JobType
+	Factory func(params json.RawMessage) (TargetFunc, error)
+}
+
+func (h *Handler) RegisterJobType(jt JobType) error {
+	if jt.Name == "" {
+		return errors.New("job type name required")
+	}
+	if jt.Factory == nil {
+		return fmt.Errorf("job type '%s' missing factory", jt.Name)
+	}
+	for name, p := range jt.Params {
+		if _, ok := paramTypeMap[p.Type]; !ok {
+			return fmt.Errorf("job type '%s' parameter '%s' has unknown type '%s'", jt.Name, name, p.Type)
+		}
+	}
+	h.tMu.Lock()
+	defer h.tMu.Unlock()
+	if _, ok := h.types[jt.Name]; ok {
+		return fmt.Errorf("job type '%s' already registered", jt.Name)
+	}
+	h.types[jt.Name] = jt
+	if jt.Resumable && h.opt.checkpoints != nil {
+		h.resumeJobs(jt)
+	}
+	return nil
+}
+
+func (h *Handler) JobTypes(_ context.Context) ([]lib.JobType, error) {
+	h.tMu.RLock()
+	defer h.tMu.RUnlock()
+	types := make([]lib.JobType, 0, len(h.types))
+	for _, jt := range h.types {
+		types = append(types, jt.JobType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].Name < types[j].Name
+	})
+	return types, nil
+}
+
+func (h *Handler) Submit(ctx context.Context, typeName string, params json.RawMessage, opts ...CreateOption) (string, error) {
+	jt, params, tFunc, err := h.targetFunc(typeName, params)
+	if err != nil {
+		return "", err
+	}
+	if jt.Resumable {
+		opts = append(opts, func(o *createOptions) {
+			o.checkpoint = &CheckpointRecord{Params: params}
+		})
+	}
+	return h.create(ctx, lib.Job{Description: jt.Description, Type: jt.Name}, tFunc, opts)
+}
+
+// targetFunc validates the parameters and creates a target function of the job type.
+func (h *Handler) targetFunc(typeName string, params json.RawMessage) (JobType, json.RawMessage, TargetFunc, error) {
+	h.tMu.RLock()
+	jt, ok := h.types[typeName]
+	h.tMu.RUnlock()
+	if !ok {
+		err := fmt.Errorf("job type '%s' not found", typeName)
+		err = h.opt.notFoundErr(err)
+		return JobType{}, nil, nil, err
+	}
+	if len(bytes.TrimSpace(params)) == 0 {
+		params = json.RawMessage("{}")
+	}
+	if err := validateParams(jt.Params, params); err != nil {
+		err = h.opt.invalidInputErr(err)
+		return JobType{}, nil, nil, err
+	}
+	tFunc, err := jt.Factory(params)
+	if err != nil {
+		err = h.opt.invalidInputErr(err)
+		return JobType{}, nil, nil, err
+	}
+	return jt, params, tFunc, nil
+}
+
+func validateParams(schema map[string]lib.JobParam, params json.RawMessage) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(params, &values); err != nil {
+		return fmt.Errorf("invalid parameters: %s", err)
+	}
+	if values == nil {
+		return errors.New("invalid parameters: expected object")
+	}
+	for name := range values {
+		if _, ok := schema[name]; !ok {
+			return fmt.Errorf("unknown parameter '%s'", name)
+		}
+	}
+	for name, p := range schema {
+		raw, ok := values[name]
+		if !ok || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if p.Required {
+				return fmt.Errorf("missing required parameter '%s'", name)
+			}
+			continue
+		}
+		var v any
+		if err := json.Unmarshal(raw, &v); err != nil {
+			return fmt.Errorf("invalid parameter '%s': %s", name, err)
+		}
+		if !checkParamType(p.Type, v) {
+			return fmt.Errorf("invalid parameter '%s': expected %s", name, p.Type)
+		}
+	}
+	return nil
+}
+
+func checkParamType(t lib.ParamType, v any) bool {
+	switch t {
+	case lib.ParamString:
+		_, ok := v.(string)
+		return ok
+	case lib.ParamNumber:
+		_, ok := v.(float64)
+		return ok
+	case lib.ParamInteger:
+		f, ok := v.(float64)
+		return ok && f == math.Trunc(f)
+	case lib.ParamBool:
+		_, ok := v.(bool)
+		return ok
+	case lib.ParamObject:
+		_, ok := v.(map[string]any)
+		return ok
+	case lib.ParamArray:
+		_, ok := v.([]any)
+		return ok
+	}
+	return false
+}
+
+var paramTypeMap = map[lib.ParamType]struct{}{
+	lib.ParamString:  {},
+	lib.ParamNumber:  {},
+	lib.ParamInteger: {},
+	lib.ParamBool:    {},
+	lib.ParamObject:  {},
+	lib.ParamArray:   {},
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"sort"
+	"time"
+)
+
+const SnapshotVersion = 1
+
+// SnapshotHeader is the first line of a snapshot, followed by one lib.Job per line.
+type SnapshotHeader struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Count   int       `json:"count"`
+}
+
+func (h *Handler) Export(_ context.Context, w io.Writer) error {
+	h.mu.RLock()
+	jobs := make([]lib.Job, 0, len(h.jobs))
+	for _, v := range h.jobs {
+		jobs = append(jobs, v.Meta())
+	}
+	h.mu.RUnlock()
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Created.Before(jobs[j].Created)
+	})
+	enc := json.NewEncoder(w)
+	err := enc.Encode(SnapshotHeader{
+		Version: SnapshotVersion,
+		Created: h.opt.clock.Now().UTC(),
+		Count:   len(jobs),
+	})
+	if err != nil {
+		err = h.opt.internalErr(err)
+		return err
+	}
+	for _, j := range jobs {
+		if err = enc.Encode(j); err != nil {
+			err = h.opt.internalErr(err)
+			return err
+		}
+	}
+	return nil
+}
+
+// Import restores jobs from a snapshot as read-only entries. Jobs with an already known ID are skipped,
+// jobs that were pending or running are marked as canceled.
+func (h *Handler) Import(_ context.Context, r io.Reader) (int, error) {
+	dec := json.NewDecoder(r)
+	var header SnapshotHeader
+	if err := dec.Decode(&header); err != nil {
+		err = fmt.Errorf("decoding snapshot header failed: %s", err)
+		err = h.opt.invalidInputErr(err)
+		return 0, err
+	}
+	if header.Version != SnapshotVersion {
+		err := fmt.Errorf("unsupported snapshot version '%d'", header.Version)
+		err = h.opt.invalidInputErr(err)
+		return 0, err
+	}
+	var jobs []lib.Job
+	for {
+		var j lib.Job
+		if err := dec.Decode(&j); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			err = fmt.Errorf("decoding snapshot job failed: %s", err)
+			err = h.opt.invalidInputErr(err)
+			return 0, err
+		}
+		if j.ID == "" {
+			err := errors.New("snapshot contains job without id")
+			err = h.opt.invalidInputErr(err)
+			return 0, err
+		}
+		jobs = append(jobs, j)
+	}
+	n := 0
+	tNow := h.opt.clock.Now().UTC()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, j := range jobs {
+		if _, ok := h.jobs[j.ID]; ok {
+			h.opt.warningf("skipping import of job '%s': already exists", j.ID)
+			continue
+		}
+		if j.Completed == nil && j.Canceled == nil {
+			// pending or running jobs were lost with the exporting handler
+			j.Canceled = &tNow
+		}
+		j.QueuePosition = 0
+		ctx, cf := context.WithCancel(h.ctx)
+		cf()
+		rj := &job{
+			ctx:      ctx,
+			cFunc:    cf,
+			readOnly: true,
+			opt:      &h.opt,
+			Job:      j,
+		}
+		h.jobs[j.ID] = rj
+		h.stats.update(rj)
+		n++
+	}
+	return n, nil
+}
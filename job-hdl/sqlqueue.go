@@ -0,0 +1,518 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"github.com/google/uuid"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultLeaseTTL          = 30 * time.Second
+	DefaultLeasePollInterval = 5 * time.Second
+	DefaultLeaseWorkers      = 1
+)
+
+type leaseOptions struct {
+	ttl          time.Duration
+	pollInterval time.Duration
+	workers      int
+	instanceID   string
+}
+
+type LeaseOption func(*leaseOptions)
+
+// WithLeaseTTL sets how long a claimed job is reserved for an instance without renewal, defaults to DefaultLeaseTTL.
+func WithLeaseTTL(d time.Duration) LeaseOption {
+	return func(o *leaseOptions) {
+		o.ttl = d
+	}
+}
+
+// WithLeasePollInterval sets the interval for renewing leases and claiming jobs, defaults to DefaultLeasePollInterval.
+// Should be well below the lease TTL.
+func WithLeasePollInterval(d time.Duration) LeaseOption {
+	return func(o *leaseOptions) {
+		o.pollInterval = d
+	}
+}
+
+// WithLeaseWorkers sets the number of jobs an instance runs at once, defaults to DefaultLeaseWorkers.
+func WithLeaseWorkers(n int) LeaseOption {
+	return func(o *leaseOptions) {
+		o.workers = n
+	}
+}
+
+// WithInstanceID sets the ID recorded as lease owner, defaults to a random UUID.
+func WithInstanceID(id string) LeaseOption {
+	return func(o *leaseOptions) {
+		o.instanceID = id
+	}
+}
+
+// SQLQueue shares jobs of registered job types between service instances via a SQL table. Instances
+// claim pending jobs with expiring leases, run them with the local Handler and renew the leases while
+// running. Jobs whose lease expired, e.g. because an instance stopped, are claimed by other instances.
+// Queries use '?' placeholders, the table is created via SQLQueueMigration.
+type SQLQueue struct {
+	db      *sql.DB
+	hdl     *Handler
+	opt     leaseOptions
+	mu      sync.Mutex
+	leased  map[string]*lease
+	started bool
+	dChan   chan struct{}
+}
+
+type lease struct {
+	canceled bool
+}
+
+func NewSQLQueue(db *sql.DB, hdl *Handler, opts ...LeaseOption) *SQLQueue {
+	o := leaseOptions{
+		ttl:          DefaultLeaseTTL,
+		pollInterval: DefaultLeasePollInterval,
+		workers:      DefaultLeaseWorkers,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.instanceID == "" {
+		o.instanceID = uuid.NewString()
+	}
+	return &SQLQueue{
+		db:     db,
+		hdl:    hdl,
+		opt:    o,
+		leased: make(map[string]*lease),
+		dChan:  make(chan struct{}),
+	}
+}
+
+const (
+	leaseCreateTable = `CREATE TABLE IF NOT EXISTS jobs (
+	id VARCHAR(64) NOT NULL PRIMARY KEY,
+	type VARCHAR(256) NOT NULL,
+	params TEXT NOT NULL,
+	description TEXT NOT NULL,
+	owner VARCHAR(256) NOT NULL,
+	priority INTEGER NOT NULL,
+	created BIGINT NOT NULL,
+	started BIGINT,
+	completed BIGINT,
+	canceled BIGINT,
+	result TEXT,
+	error TEXT,
+	lease_owner VARCHAR(64),
+	lease_expires BIGINT,
+	attempts INTEGER NOT NULL DEFAULT 0
+)`
+	leaseColumns  = "id, type, params, description, owner, priority, created, started, completed, canceled, result, error"
+	leaseExists   = "SELECT id FROM jobs WHERE 1 = 0"
+	leaseInsert   = "INSERT INTO jobs (id, type, params, description, owner, priority, created) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	leaseSelect   = "SELECT " + leaseColumns + " FROM jobs"
+	leaseSelectID = leaseSelect + " WHERE id = ?"
+	leaseCancel   = "UPDATE jobs SET canceled = ? WHERE id = ? AND completed IS NULL AND canceled IS NULL"
+	leaseRenew    = "UPDATE jobs SET lease_expires = ? WHERE id = ? AND lease_owner = ?"
+	leaseCanceled = "SELECT canceled FROM jobs WHERE id = ?"
+	leasePending  = leaseSelect + " WHERE completed IS NULL AND canceled IS NULL AND (lease_owner IS NULL OR lease_expires < ?) ORDER BY priority DESC, created ASC LIMIT ?"
+	leaseClaim    = "UPDATE jobs SET lease_owner = ?, lease_expires = ?, started = ?, attempts = attempts + 1 WHERE id = ? AND completed IS NULL AND canceled IS NULL AND (lease_owner IS NULL OR lease_expires < ?)"
+	leaseComplete = "UPDATE jobs SET started = ?, completed = ?, result = ?, error = ?, lease_owner = NULL, lease_expires = NULL WHERE id = ? AND lease_owner = ?"
+	leaseRelease  = "UPDATE jobs SET started = NULL, lease_owner = NULL, lease_expires = NULL WHERE id = ? AND lease_owner = ?"
+)
+
+// SQLQueueMigration creates the 'jobs' table used by SQLQueue. Implements the Migration interface
+// of the sql-db-hdl module and can be passed to its InitDB function.
+type SQLQueueMigration struct{}
+
+func (SQLQueueMigration) Required(ctx context.Context, db *sql.DB, timeout time.Duration) (bool, error) {
+	ctxWt, cf := context.WithTimeout(ctx, timeout)
+	defer cf()
+	rows, err := db.QueryContext(ctxWt, leaseExists)
+	if err != nil {
+		return true, nil
+	}
+	return false, rows.Close()
+}
+
+func (SQLQueueMigration) Run(ctx context.Context, db *sql.DB, timeout time.Duration) error {
+	ctxWt, cf := context.WithTimeout(ctx, timeout)
+	defer cf()
+	_, err := db.ExecContext(ctxWt, leaseCreateTable)
+	return err
+}
+
+// Submit validates the parameters against the registered job type and stores a pending job. Only
+// WithOwner and WithPriority are applied.
+func (q *SQLQueue) Submit(ctx context.Context, typeName string, params json.RawMessage, opts ...CreateOption) (string, error) {
+	jt, params, _, err := q.hdl.targetFunc(typeName, params)
+	if err != nil {
+		return "", err
+	}
+	cOpt := newCreateOptions(opts)
+	uid, err := uuid.NewRandom()
+	if err != nil {
+		return "", q.hdl.opt.internalErr(err)
+	}
+	_, err = q.db.ExecContext(ctx, leaseInsert,
+		uid.String(), jt.Name, string(params), jt.Description, cOpt.owner, cOpt.priority, q.now().UnixNano())
+	if err != nil {
+		return "", q.hdl.opt.internalErr(err)
+	}
+	return uid.String(), nil
+}
+
+type leaseRow struct {
+	lib.Job
+	params json.RawMessage
+}
+
+func scanLeaseRow(s interface{ Scan(dest ...any) error }) (leaseRow, error) {
+	var r leaseRow
+	var params string
+	var created int64
+	var started, completed, canceled sql.NullInt64
+	var result, jErr sql.NullString
+	if err := s.Scan(&r.ID, &r.Type, &params, &r.Description, &r.Owner, &r.Priority, &created, &started, &completed, &canceled, &result, &jErr); err != nil {
+		return leaseRow{}, err
+	}
+	r.params = json.RawMessage(params)
+	r.Created = time.Unix(0, created).UTC()
+	r.Started = nullTime(started)
+	r.Completed = nullTime(completed)
+	r.Canceled = nullTime(canceled)
+	if result.Valid {
+		if err := json.Unmarshal([]byte(result.String), &r.Result); err != nil {
+			return leaseRow{}, err
+		}
+	}
+	if jErr.Valid {
+		if err := json.Unmarshal([]byte(jErr.String), &r.Error); err != nil {
+			return leaseRow{}, err
+		}
+	}
+	return r, nil
+}
+
+func nullTime(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(0, v.Int64).UTC()
+	return &t
+}
+
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+func (q *SQLQueue) Get(ctx context.Context, id string) (lib.Job, error) {
+	r, err := scanLeaseRow(q.db.QueryRowContext(ctx, leaseSelectID, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return lib.Job{}, q.hdl.opt.notFoundErr(fmt.Errorf("%s not found", id))
+		}
+		return lib.Job{}, q.hdl.opt.internalErr(err)
+	}
+	return r.Job, nil
+}
+
+func (q *SQLQueue) List(ctx context.Context, filter lib.JobFilter) ([]lib.Job, error) {
+	if err := q.hdl.validateFilter(filter); err != nil {
+		return nil, err
+	}
+	tNow := q.now()
+	rows, err := q.db.QueryContext(ctx, leaseSelect)
+	if err != nil {
+		return nil, q.hdl.opt.internalErr(err)
+	}
+	defer rows.Close()
+	var jobs []lib.Job
+	for rows.Next() {
+		r, err := scanLeaseRow(rows)
+		if err != nil {
+			return nil, q.hdl.opt.internalErr(err)
+		}
+		if check(filter, r.Job, tNow) {
+			jobs = append(jobs, r.Job)
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return nil, q.hdl.opt.internalErr(err)
+	}
+	sortJobs(jobs, filter, tNow)
+	return jobs, nil
+}
+
+// Cancel marks the job as canceled. A running job is canceled by the instance holding its lease.
+func (q *SQLQueue) Cancel(ctx context.Context, id string) error {
+	res, err := q.db.ExecContext(ctx, leaseCancel, q.now().UnixNano(), id)
+	if err != nil {
+		return q.hdl.opt.internalErr(err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return q.hdl.opt.internalErr(err)
+	} else if n == 0 {
+		if _, err = q.Get(ctx, id); err != nil {
+			return err
+		}
+	}
+	q.mu.Lock()
+	l, ok := q.leased[id]
+	if ok {
+		l.canceled = true
+	}
+	q.mu.Unlock()
+	if ok {
+		return q.hdl.Cancel(ctx, id)
+	}
+	return nil
+}
+
+// Poll renews the leases of running jobs and claims pending or expired jobs while workers are available.
+func (q *SQLQueue) Poll(ctx context.Context) error {
+	if err := q.renew(ctx); err != nil {
+		return err
+	}
+	return q.claim(ctx)
+}
+
+func (q *SQLQueue) renew(ctx context.Context) error {
+	q.mu.Lock()
+	ids := make([]string, 0, len(q.leased))
+	for id := range q.leased {
+		ids = append(ids, id)
+	}
+	q.mu.Unlock()
+	expires := q.now().Add(q.opt.ttl).UnixNano()
+	for _, id := range ids {
+		res, err := q.db.ExecContext(ctx, leaseRenew, expires, id, q.opt.instanceID)
+		if err != nil {
+			return q.hdl.opt.internalErr(err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return q.hdl.opt.internalErr(err)
+		}
+		if n == 0 {
+			q.hdl.opt.warningf("lease of job '%s' lost", id)
+			q.mu.Lock()
+			delete(q.leased, id)
+			q.mu.Unlock()
+			q.cancelLocal(ctx, id)
+			continue
+		}
+		var canceled sql.NullInt64
+		if err = q.db.QueryRowContext(ctx, leaseCanceled, id).Scan(&canceled); err != nil {
+			return q.hdl.opt.internalErr(err)
+		}
+		if canceled.Valid {
+			q.mu.Lock()
+			l, ok := q.leased[id]
+			cancel := ok && !l.canceled
+			if cancel {
+				l.canceled = true
+			}
+			q.mu.Unlock()
+			if cancel {
+				q.cancelLocal(ctx, id)
+			}
+		}
+	}
+	return nil
+}
+
+func (q *SQLQueue) cancelLocal(ctx context.Context, id string) {
+	if err := q.hdl.Cancel(ctx, id); err != nil {
+		q.hdl.opt.warningf("canceling job '%s' failed: %s", id, err)
+	}
+}
+
+func (q *SQLQueue) claim(ctx context.Context) error {
+	q.mu.Lock()
+	free := q.opt.workers - len(q.leased)
+	q.mu.Unlock()
+	if free <= 0 {
+		return nil
+	}
+	tNow := q.now()
+	rows, err := q.db.QueryContext(ctx, leasePending, tNow.UnixNano(), free)
+	if err != nil {
+		return q.hdl.opt.internalErr(err)
+	}
+	var candidates []leaseRow
+	for rows.Next() {
+		r, err := scanLeaseRow(rows)
+		if err != nil {
+			rows.Close()
+			return q.hdl.opt.internalErr(err)
+		}
+		candidates = append(candidates, r)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return q.hdl.opt.internalErr(err)
+	}
+	for _, r := range candidates {
+		res, err := q.db.ExecContext(ctx, leaseClaim,
+			q.opt.instanceID, tNow.Add(q.opt.ttl).UnixNano(), tNow.UnixNano(), r.ID, tNow.UnixNano())
+		if err != nil {
+			return q.hdl.opt.internalErr(err)
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return q.hdl.opt.internalErr(err)
+		} else if n == 0 {
+			continue
+		}
+		if err = q.run(ctx, r); err != nil {
+			q.hdl.opt.errorf("starting job '%s' failed: %s", r.ID, err)
+			q.release(ctx, r.ID)
+		}
+	}
+	return nil
+}
+
+func (q *SQLQueue) run(ctx context.Context, r leaseRow) error {
+	_, _, tFunc, err := q.hdl.targetFunc(r.Type, r.params)
+	if err != nil {
+		return err
+	}
+	q.hdl.forget(r.ID)
+	q.mu.Lock()
+	q.leased[r.ID] = &lease{}
+	q.mu.Unlock()
+	_, err = q.hdl.create(ctx, lib.Job{Description: r.Description, Type: r.Type}, tFunc, []CreateOption{
+		WithOwner(r.Owner),
+		WithPriority(r.Priority),
+		WithCallback(q.complete),
+		func(o *createOptions) {
+			o.id = r.ID
+			o.created = r.Created
+		},
+	})
+	if err != nil {
+		q.mu.Lock()
+		delete(q.leased, r.ID)
+		q.mu.Unlock()
+	}
+	return err
+}
+
+// complete stores the final state of a job if the lease is still held. A job failing after the
+// handler context is done is released instead, so another instance can claim it right away.
+func (q *SQLQueue) complete(job lib.Job) {
+	q.mu.Lock()
+	l := q.leased[job.ID]
+	delete(q.leased, job.ID)
+	q.mu.Unlock()
+	ctx, cf := context.WithTimeout(context.WithoutCancel(q.hdl.ctx), q.opt.ttl)
+	defer cf()
+	if q.hdl.ctx.Err() != nil && job.Error != nil && (l == nil || !l.canceled) {
+		q.release(ctx, job.ID)
+		return
+	}
+	var result, jErr any
+	if job.Error != nil {
+		b, err := json.Marshal(job.Error)
+		if err != nil {
+			q.hdl.opt.errorf("encoding error of job '%s' failed: %s", job.ID, err)
+		} else {
+			jErr = string(b)
+		}
+	} else if job.Completed != nil {
+		b, err := json.Marshal(job.Result)
+		if err != nil {
+			q.hdl.opt.errorf("encoding result of job '%s' failed: %s", job.ID, err)
+		} else {
+			result = string(b)
+		}
+	}
+	res, err := q.db.ExecContext(ctx, leaseComplete, timeValue(job.Started), timeValue(job.Completed), result, jErr, job.ID, q.opt.instanceID)
+	if err != nil {
+		q.hdl.opt.errorf("storing job '%s' failed: %s", job.ID, err)
+		return
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		q.hdl.opt.warningf("discarding state of job '%s': lease lost", job.ID)
+	}
+}
+
+func (q *SQLQueue) release(ctx context.Context, id string) {
+	_, err := q.db.ExecContext(ctx, leaseRelease, id, q.opt.instanceID)
+	if err != nil {
+		q.hdl.opt.errorf("releasing job '%s' failed: %s", id, err)
+	}
+}
+
+func (q *SQLQueue) now() time.Time {
+	return q.hdl.opt.clock.Now().UTC()
+}
+
+func (q *SQLQueue) Start(ctx context.Context) {
+	q.mu.Lock()
+	if !q.started {
+		q.started = true
+		q.mu.Unlock()
+		go q.loop(ctx)
+	} else {
+		q.mu.Unlock()
+	}
+}
+
+func (q *SQLQueue) Wait() {
+	<-q.dChan
+}
+
+func (q *SQLQueue) loop(ctx context.Context) {
+	ticker := time.NewTicker(q.opt.pollInterval)
+	defer ticker.Stop()
+	for {
+		if err := q.Poll(ctx); err != nil {
+			q.hdl.opt.errorf("polling job queue failed: %s", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			close(q.dChan)
+			return
+		}
+	}
+}
+
+// forget removes a finished local job so a job claimed again can be recreated with its ID.
+func (h *Handler) forget(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if j, ok := h.jobs[id]; ok {
+		m := j.Meta()
+		if m.Completed != nil || m.Canceled != nil {
+			h.stats.remove(j)
+			delete(h.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	_ "modernc.org/sqlite"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T, ctx context.Context, db *sql.DB, clock *jhtest.Clock, name string) (*job_hdl.SQLQueue, *job_hdl.Handler, *jhtest.Executor) {
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(ctx, exec, job_hdl.WithClock(clock))
+	err := hdl.RegisterJobType(job_hdl.JobType{
+		JobType: lib.JobType{
+			Name:   "echo",
+			Params: map[string]lib.JobParam{"v": {Type: lib.ParamInteger, Required: true}},
+		},
+		Factory: func(params json.RawMessage) (job_hdl.TargetFunc, error) {
+			var p struct{ V int }
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+			return jhtest.Result(map[string]any{"v": p.V, "instance": name}, nil), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = hdl.RegisterJobType(job_hdl.JobType{
+		JobType: lib.JobType{Name: "wait"},
+		Factory: func(json.RawMessage) (job_hdl.TargetFunc, error) {
+			return func(ctx context.Context, _ context.CancelFunc) (any, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := job_hdl.SQLQueueMigration{}
+	if ok, err := m.Required(context.Background(), db, time.Second); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		if err = m.Run(context.Background(), db, time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return job_hdl.NewSQLQueue(db, hdl, job_hdl.WithInstanceID(name), job_hdl.WithLeaseTTL(30*time.Second)), hdl, exec
+}
+
+func assertRunBy(t *testing.T, q *job_hdl.SQLQueue, id string, v float64, instance string) {
+	t.Helper()
+	j, err := q.Get(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jhtest.AssertStatus(t, j, lib.JobCompleted)
+	res, _ := j.Result.(map[string]any)
+	if res["v"] != v || res["instance"] != instance {
+		t.Errorf("result is '%v', expected '%v' from '%s'", j.Result, v, instance)
+	}
+}
+
+func TestSQLQueue(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	clock := jhtest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	qA, hdlA, execA := newTestQueue(t, ctx, db, clock, "a")
+	qB, hdlB, execB := newTestQueue(t, ctx, db, clock, "b")
+	if _, err = qA.Submit(ctx, "echo", json.RawMessage(`{}`)); err == nil {
+		t.Error("invalid parameters accepted")
+	}
+	var ids []string
+	for _, params := range []string{`{"v": 1}`, `{"v": 2}`} {
+		id, err := qA.Submit(ctx, "echo", json.RawMessage(params))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, id)
+		clock.Advance(time.Millisecond)
+	}
+	j, err := qB.Get(ctx, ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	jhtest.AssertStatus(t, j, lib.JobPending)
+	for _, q := range []*job_hdl.SQLQueue{qA, qB} {
+		if err = q.Poll(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	execB.RunAll()
+	assertRunBy(t, qA, ids[1], 2, "b")
+	clock.Advance(31 * time.Second)
+	if err = qB.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	execB.RunAll()
+	assertRunBy(t, qA, ids[0], 1, "b")
+	if err = qA.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if execA.RunAll() != 0 {
+		t.Error("job with lost lease run")
+	}
+	jhtest.AssertStatus(t, jhtest.GetJob(t, hdlA, ids[0]), lib.JobCanceled)
+	id, err := qB.Submit(ctx, "echo", json.RawMessage(`{"v": 3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = qA.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		clock.Advance(20 * time.Second)
+		for _, q := range []*job_hdl.SQLQueue{qA, qB} {
+			if err = q.Poll(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if _, err = hdlB.Get(ctx, id); err == nil {
+		t.Error("job with renewed lease taken over")
+	}
+	execA.RunAll()
+	assertRunBy(t, qB, id, 3, "a")
+	if id, err = qA.Submit(ctx, "echo", json.RawMessage(`{"v": 4}`)); err != nil {
+		t.Fatal(err)
+	}
+	if err = qB.Cancel(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if err = qA.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	jobs, err := qA.List(ctx, lib.JobFilter{Status: lib.JobCanceled})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != id || execA.Pending() != 0 {
+		t.Errorf("canceled jobs are '%v', expected '%s'", jobs, id)
+	}
+}
+
+func TestSQLQueueShutdown(t *testing.T) {
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(t.TempDir(), "jobs.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+	ctx := context.Background()
+	ctxA, cfA := context.WithCancel(ctx)
+	defer cfA()
+	clock := jhtest.NewClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	qA, hdlA, execA := newTestQueue(t, ctxA, db, clock, "a")
+	qB, hdlB, _ := newTestQueue(t, ctx, db, clock, "b")
+	id, err := qA.Submit(ctx, "wait", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = qA.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err = qB.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hdlB.Get(ctx, id); err == nil {
+		t.Fatal("leased job claimed")
+	}
+	sub := hdlA.Subscribe("")
+	defer sub.Close()
+	done := make(chan struct{})
+	go func() {
+		execA.RunAll()
+		close(done)
+	}()
+	for e := range sub.C {
+		if e.Job.ID == id && e.Job.Started != nil {
+			break
+		}
+	}
+	cfA()
+	<-done
+	j, err := qB.Get(ctx, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jhtest.AssertStatus(t, j, lib.JobPending)
+	if err = qB.Poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hdlB.Get(ctx, id); err != nil {
+		t.Errorf("released job not claimed: %s", err)
+	}
+}
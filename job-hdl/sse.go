@@ -0,0 +1,100 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"net/http"
+	"time"
+)
+
+const DefaultHeartbeat = 15 * time.Second
+
+type sseHandler struct {
+	h         *Handler
+	heartbeat time.Duration
+}
+
+// NewSSEHandler returns an http.Handler streaming job events as Server-Sent Events. Events can be
+// filtered via the query parameters of lib.JobFilter.Values and lib.QueryJobID. Streams are resumed
+// via the Last-Event-ID header. A comment is written every heartbeat interval, defaults to
+// DefaultHeartbeat if not greater than zero.
+func NewSSEHandler(h *Handler, heartbeat time.Duration) http.Handler {
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	return &sseHandler{h: h, heartbeat: heartbeat}
+}
+
+func (s *sseHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter, err := lib.ParseJobFilter(query)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err = s.h.validateFilter(filter); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jID := query.Get(lib.QueryJobID)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	sub := s.h.Subscribe(r.Header.Get(lib.LastEventIDHeader))
+	defer sub.Close()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	ticker := time.NewTicker(s.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.h.ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err = fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if (jID != "" && e.Job.ID != jID) || !check(filter, e.Job, s.h.opt.clock.Now().UTC()) {
+				continue
+			}
+			b, err := json.Marshal(e.Job)
+			if err != nil {
+				s.h.opt.errorf("encoding job event failed: %s", err)
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", e.ID, lib.JobEventName, b); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
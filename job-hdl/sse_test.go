@@ -0,0 +1,99 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"context"
+	"errors"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var errStop = errors.New("stop")
+
+func collectEvents(t *testing.T, client *lib.StreamClient, filter lib.JobFilter, jID string, n int) []lib.JobEvent {
+	t.Helper()
+	ctx, cf := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cf()
+	var events []lib.JobEvent
+	err := client.Stream(ctx, filter, jID, func(e lib.JobEvent) error {
+		events = append(events, e)
+		if len(events) == n {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) {
+		t.Fatal(err)
+	}
+	return events
+}
+
+func TestSSE(t *testing.T) {
+	hdl, exec, _ := newTestHandler()
+	ctx := context.Background()
+	srv := httptest.NewServer(job_hdl.NewSSEHandler(hdl, 10*time.Millisecond))
+	defer srv.Close()
+	id, err := hdl.Create(ctx, "test", jhtest.Result(1, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = hdl.Create(ctx, "other", jhtest.Result(2, nil)); err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	client := &lib.StreamClient{URL: srv.URL, RetryDelay: 10 * time.Millisecond, MaxFailures: 3, LastEventID: "0-0"}
+	events := collectEvents(t, client, lib.JobFilter{}, id, 3)
+	for i, status := range []lib.JobStatus{lib.JobPending, lib.JobRunning, lib.JobCompleted} {
+		if events[i].Job.ID != id {
+			t.Errorf("event %d has job '%s', expected '%s'", i, events[i].Job.ID, id)
+		}
+		jhtest.AssertStatus(t, events[i].Job, status)
+	}
+	client.LastEventID = events[1].ID
+	resumed := collectEvents(t, client, lib.JobFilter{}, id, 1)
+	if resumed[0].ID != events[2].ID {
+		t.Errorf("resumed with event '%s', expected '%s'", resumed[0].ID, events[2].ID)
+	}
+	// resuming after a known event delivers the new job even if it is created before the stream is connected
+	client.LastEventID = events[2].ID
+	live := make(chan lib.JobEvent, 1)
+	sCtx, cf := context.WithTimeout(ctx, 5*time.Second)
+	defer cf()
+	go func() {
+		_ = client.Stream(sCtx, lib.JobFilter{Status: lib.JobPending}, "", func(e lib.JobEvent) error {
+			live <- e
+			return errStop
+		})
+	}()
+	id3, err := hdl.Create(ctx, "live", jhtest.Result(3, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case e := <-live:
+		if e.Job.ID != id3 {
+			t.Errorf("received job '%s', expected '%s'", e.Job.ID, id3)
+		}
+	case <-sCtx.Done():
+		t.Error("no live event received")
+	}
+}
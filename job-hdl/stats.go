@@ -0,0 +1,177 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"sort"
+	"sync"
+	"time"
+)
+
+const DefaultStatsRetention = 24 * time.Hour
+
+const maxStatsSamples = 100000
+
+type durationSample struct {
+	time     time.Time
+	duration time.Duration
+	failed   bool
+}
+
+// statsCollector maintains job counts per state and duration samples of recently started and
+// completed jobs as jobs change, so that statistics do not require scanning all jobs.
+type statsCollector struct {
+	mu        sync.Mutex
+	opt       *options
+	states    map[*job]lib.JobStatus
+	counts    map[lib.JobStatus]int
+	queueTime []durationSample
+	runTime   []durationSample
+}
+
+func newStatsCollector(opt *options) *statsCollector {
+	return &statsCollector{
+		opt:    opt,
+		states: make(map[*job]lib.JobStatus),
+		counts: make(map[lib.JobStatus]int),
+	}
+}
+
+func jobState(m lib.Job) lib.JobStatus {
+	switch {
+	case m.Canceled != nil:
+		return lib.JobCanceled
+	case m.Completed != nil && m.Error != nil:
+		return lib.JobError
+	case m.Completed != nil:
+		return lib.JobOK
+	case m.Started != nil:
+		return lib.JobRunning
+	default:
+		return lib.JobPending
+	}
+}
+
+func (s *statsCollector) update(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m := j.Meta()
+	prev, known := s.states[j]
+	state := jobState(m)
+	if known && prev == state {
+		return
+	}
+	if known {
+		s.counts[prev]--
+	}
+	s.counts[state]++
+	s.states[j] = state
+	if prev == lib.JobPending && m.Started != nil {
+		s.queueTime = s.add(s.queueTime, durationSample{time: *m.Started, duration: m.Started.Sub(m.Created)})
+	}
+	if prev == lib.JobRunning && m.Completed != nil {
+		s.runTime = s.add(s.runTime, durationSample{time: *m.Completed, duration: m.Completed.Sub(*m.Started), failed: m.Error != nil})
+	}
+}
+
+func (s *statsCollector) add(samples []durationSample, sample durationSample) []durationSample {
+	samples = append(samples, sample)
+	cutoff := s.opt.clock.Now().Add(-s.opt.statsRetention)
+	i := 0
+	for i < len(samples) && (samples[i].time.Before(cutoff) || len(samples)-i > maxStatsSamples) {
+		i++
+	}
+	if i > 0 {
+		samples = append(samples[:0], samples[i:]...)
+	}
+	return samples
+}
+
+func (s *statsCollector) remove(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if state, ok := s.states[j]; ok {
+		s.counts[state]--
+		delete(s.states, j)
+	}
+}
+
+func windowDurations(samples []durationSample, since time.Time) (avg, p95 time.Duration, n, failed int) {
+	var ds []time.Duration
+	var sum time.Duration
+	for _, sample := range samples {
+		if sample.time.Before(since) {
+			continue
+		}
+		ds = append(ds, sample.duration)
+		sum += sample.duration
+		if sample.failed {
+			failed++
+		}
+	}
+	n = len(ds)
+	if n == 0 {
+		return
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		return ds[i] < ds[j]
+	})
+	return sum / time.Duration(n), ds[(n*95+99)/100-1], n, failed
+}
+
+// Stats summarizes the state of all jobs. Durations and the failure rate consider jobs started or
+// completed within the window, which is limited to the retention configured via WithStatsRetention.
+func (h *Handler) Stats(_ context.Context, window time.Duration) (lib.JobStats, error) {
+	if window <= 0 || window > h.opt.statsRetention {
+		window = h.opt.statsRetention
+	}
+	since := h.opt.clock.Now().UTC().Add(-window)
+	stats := lib.JobStats{
+		Counts: make(map[lib.JobStatus]int),
+		Window: window,
+	}
+	h.stats.mu.Lock()
+	for state, n := range h.stats.counts {
+		stats.Counts[state] = n
+	}
+	stats.AvgRunTime, stats.P95RunTime, stats.Finished, stats.Failed = windowDurations(h.stats.runTime, since)
+	stats.AvgQueueTime, stats.P95QueueTime, _, _ = windowDurations(h.stats.queueTime, since)
+	h.stats.mu.Unlock()
+	stats.Counts[lib.JobCompleted] = stats.Counts[lib.JobOK] + stats.Counts[lib.JobError]
+	if stats.Finished > 0 {
+		stats.FailureRate = float64(stats.Failed) / float64(stats.Finished)
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var oldest *job
+	for _, p := range h.pools {
+		for _, j := range p.queue.jobs {
+			if oldest == nil || j.Created.Before(oldest.Created) {
+				oldest = j
+			}
+		}
+	}
+	stats.Stuck, stats.Abandoned = h.flagged()
+	if oldest != nil {
+		m := oldest.Meta()
+		m.QueuePosition = oldest.pool.queue.position(oldest)
+		stats.OldestPending = &m
+	}
+	return stats, nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DefaultCancelGracePeriod  = 30 * time.Second
+	DefaultMaxAbandoned       = 1
+	DefaultStuckCheckInterval = 10 * time.Second
+)
+
+// CheckStuck flags running jobs exceeding the maximum runtime as stuck and canceled jobs still
+// running after the cancel grace period as abandoned. Abandoned jobs ignore cancellation and hold
+// an executor slot until their target returns. Returns the number of flagged jobs still running.
+// The handler also checks periodically in the background, see WithStuckCheckInterval.
+func (h *Handler) CheckStuck(_ context.Context) (stuck, abandoned int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	tNow := h.opt.clock.Now().UTC()
+	for _, p := range h.pools {
+		for j := range p.running {
+			if j.flag(tNow) {
+				j.changed()
+			}
+		}
+	}
+	return h.flagged()
+}
+
+// watchStuck runs CheckStuck at the configured interval until the handler context is done.
+func (h *Handler) watchStuck() {
+	ticker := time.NewTicker(h.opt.stuckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.CheckStuck(h.ctx)
+		case <-h.ctx.Done():
+			return
+		}
+	}
+}
+
+// IsHealthy checks for stuck jobs and reports false if the number of abandoned jobs reached the
+// limit set via WithMaxAbandoned. Can be registered as health function of a watchdog.
+func (h *Handler) IsHealthy() bool {
+	_, abandoned := h.CheckStuck(context.Background())
+	return h.opt.maxAbandoned <= 0 || abandoned < h.opt.maxAbandoned
+}
+
+// flagged counts running jobs flagged as stuck or abandoned, the handler lock must be held.
+func (h *Handler) flagged() (stuck, abandoned int) {
+	for _, p := range h.pools {
+		for j := range p.running {
+			m := j.Meta()
+			if m.Stuck != nil {
+				stuck++
+			}
+			if m.Abandoned != nil {
+				abandoned++
+			}
+		}
+	}
+	return
+}
+
+func (j *job) flag(tNow time.Time) bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.Started == nil || j.Completed != nil {
+		return false
+	}
+	changed := false
+	if j.opt.maxRuntime > 0 && j.Stuck == nil && tNow.Sub(*j.Started) >= j.opt.maxRuntime {
+		j.Stuck = &tNow
+		j.opt.warningf("job '%s' stuck: running for '%s'", j.ID, tNow.Sub(*j.Started))
+		changed = true
+	}
+	if j.opt.cancelGrace > 0 && j.Canceled != nil && j.Abandoned == nil && tNow.Sub(*j.Canceled) >= j.opt.cancelGrace {
+		j.Abandoned = &tNow
+		j.opt.errorf("job '%s' abandoned: still running '%s' after cancellation", j.ID, tNow.Sub(*j.Canceled))
+		changed = true
+	}
+	return changed
+}
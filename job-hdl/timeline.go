@@ -0,0 +1,222 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	TraceCatQueue   = "queue"
+	TraceCatExecute = "execute"
+	TraceCatChild   = "child"
+)
+
+// TraceEvent is an event of the Chrome trace event format, timestamps and durations are in microseconds.
+type TraceEvent struct {
+	Name string         `json:"name"`
+	Cat  string         `json:"cat,omitempty"`
+	Ph   string         `json:"ph"`
+	Ts   int64          `json:"ts"`
+	Dur  *int64         `json:"dur,omitempty"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	ID   string         `json:"id,omitempty"`
+	Bp   string         `json:"bp,omitempty"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+type TraceFile struct {
+	TraceEvents     []TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+type timelineSlice struct {
+	job        lib.Job
+	start, end time.Time
+	pid, tid   int
+}
+
+// lanes assigns each slice the lowest lane not occupied at its start.
+func lanes(slices []*timelineSlice, offset int) int {
+	sort.SliceStable(slices, func(i, j int) bool {
+		return slices[i].start.Before(slices[j].start)
+	})
+	var ends []time.Time
+	for _, s := range slices {
+		lane := -1
+		for i, end := range ends {
+			if !end.After(s.start) {
+				lane = i
+				break
+			}
+		}
+		if lane < 0 {
+			lane = len(ends)
+			ends = append(ends, time.Time{})
+		}
+		ends[lane] = s.end
+		s.tid = offset + lane + 1
+	}
+	return len(ends)
+}
+
+// ExportTrace writes the timelines of jobs matching the filter as Chrome trace event JSON. Each pool
+// is a process with worker lanes for job execution and separate lanes for queue wait. Jobs created
+// by other jobs are linked to their parent via flow events.
+func (h *Handler) ExportTrace(ctx context.Context, w io.Writer, filter lib.JobFilter) error {
+	jobs, err := h.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+	tNow := h.opt.clock.Now().UTC()
+	var origin time.Time
+	pools := make(map[string][]lib.Job)
+	for _, j := range jobs {
+		if origin.IsZero() || j.Created.Before(origin) {
+			origin = j.Created
+		}
+		pools[j.Pool] = append(pools[j.Pool], j)
+	}
+	names := make([]string, 0, len(pools))
+	for name := range pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	micros := func(t time.Time) int64 {
+		return t.Sub(origin).Microseconds()
+	}
+	events := make([]TraceEvent, 0)
+	execSlices := make(map[string]*timelineSlice)
+	firstSlices := make(map[string]*timelineSlice)
+	var slices []*timelineSlice
+	for i, name := range names {
+		pid := i + 1
+		var queued, executed []*timelineSlice
+		for _, j := range pools[name] {
+			end := tNow
+			if j.Completed != nil {
+				end = *j.Completed
+			}
+			qEnd := end
+			if j.Started != nil {
+				qEnd = *j.Started
+			} else if j.Canceled != nil {
+				qEnd = *j.Canceled
+			}
+			if j.Canceled != nil && j.Completed == nil && j.Canceled.Before(end) {
+				end = *j.Canceled
+			}
+			q := &timelineSlice{job: j, start: j.Created, end: qEnd, pid: pid}
+			queued = append(queued, q)
+			firstSlices[j.ID] = q
+			if j.Started != nil {
+				e := &timelineSlice{job: j, start: *j.Started, end: end, pid: pid}
+				executed = append(executed, e)
+				execSlices[j.ID] = e
+			}
+		}
+		workers := lanes(executed, 0)
+		queues := lanes(queued, workers)
+		events = append(events, TraceEvent{Name: "process_name", Ph: "M", Pid: pid, Args: map[string]any{"name": "pool " + name}})
+		for l := 1; l <= workers+queues; l++ {
+			tName := fmt.Sprintf("worker %d", l)
+			if l > workers {
+				tName = fmt.Sprintf("queue %d", l-workers)
+			}
+			events = append(events, TraceEvent{Name: "thread_name", Ph: "M", Pid: pid, Tid: l, Args: map[string]any{"name": tName}})
+		}
+		slices = append(slices, queued...)
+		slices = append(slices, executed...)
+	}
+	for _, s := range slices {
+		cat := TraceCatExecute
+		if firstSlices[s.job.ID] == s {
+			cat = TraceCatQueue
+		}
+		dur := s.end.Sub(s.start).Microseconds()
+		events = append(events, TraceEvent{
+			Name: traceName(s.job),
+			Cat:  cat,
+			Ph:   "X",
+			Ts:   micros(s.start),
+			Dur:  &dur,
+			Pid:  s.pid,
+			Tid:  s.tid,
+			Args: traceArgs(s.job),
+		})
+	}
+	for _, j := range jobs {
+		parent, ok := execSlices[j.ParentID]
+		if j.ParentID == "" || !ok {
+			continue
+		}
+		child := firstSlices[j.ID]
+		ts := j.Created
+		if ts.Before(parent.start) {
+			ts = parent.start
+		}
+		if ts.After(parent.end) {
+			ts = parent.end
+		}
+		events = append(events,
+			TraceEvent{Name: "spawn", Cat: TraceCatChild, Ph: "s", Ts: micros(ts), Pid: parent.pid, Tid: parent.tid, ID: j.ID},
+			TraceEvent{Name: "spawn", Cat: TraceCatChild, Ph: "f", Bp: "e", Ts: micros(child.start), Pid: child.pid, Tid: child.tid, ID: j.ID},
+		)
+	}
+	return json.NewEncoder(w).Encode(TraceFile{TraceEvents: events, DisplayTimeUnit: "ms"})
+}
+
+func traceName(j lib.Job) string {
+	switch {
+	case j.Description != "":
+		return j.Description
+	case j.Type != "":
+		return j.Type
+	default:
+		return j.ID
+	}
+}
+
+func traceArgs(j lib.Job) map[string]any {
+	args := map[string]any{
+		"id":       j.ID,
+		"priority": j.Priority,
+	}
+	if j.Type != "" {
+		args["type"] = j.Type
+	}
+	if j.Owner != "" {
+		args["owner"] = j.Owner
+	}
+	if j.ParentID != "" {
+		args["parent_id"] = j.ParentID
+	}
+	if j.Canceled != nil {
+		args["canceled"] = true
+	}
+	if j.Error != nil {
+		args["error"] = j.Error.Message
+	}
+	return args
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"context"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+)
+
+// Tracer starts spans for job queueing and execution. Implementations can adapt an
+// existing tracing library, see jhtest.Recorder for an in-memory implementation.
+type Tracer interface {
+	// Start starts a span as child of parent, which is empty for root spans.
+	Start(parent lib.SpanContext, name string, links ...lib.SpanContext) Span
+}
+
+type Span interface {
+	SpanContext() lib.SpanContext
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+const (
+	QueueSpanName   = "job.queue"
+	ExecuteSpanName = "job.execute"
+)
+
+func (j *job) startQueueSpan() {
+	if j.opt.tracer == nil {
+		j.TraceID = j.traceParent.TraceID
+		return
+	}
+	j.qSpan = j.opt.tracer.Start(j.traceParent, QueueSpanName)
+	j.setSpanAttributes(j.qSpan)
+	j.TraceID = j.qSpan.SpanContext().TraceID
+}
+
+// endQueueSpan must be called with the job lock held.
+func (j *job) endQueueSpan(err error) {
+	if j.qSpan == nil {
+		return
+	}
+	if err != nil {
+		j.qSpan.RecordError(err)
+	}
+	j.qSpan.End()
+	j.qSpan = nil
+}
+
+// startExecSpan returns the target context carrying the span context of the execution span.
+func (j *job) startExecSpan(qSc lib.SpanContext) (context.Context, Span) {
+	if j.opt.tracer == nil {
+		if j.traceParent.IsValid() {
+			return lib.ContextWithSpanContext(j.ctx, j.traceParent), nil
+		}
+		return j.ctx, nil
+	}
+	var span Span
+	if qSc.IsValid() {
+		span = j.opt.tracer.Start(j.traceParent, ExecuteSpanName, qSc)
+	} else {
+		span = j.opt.tracer.Start(j.traceParent, ExecuteSpanName)
+	}
+	j.setSpanAttributes(span)
+	return lib.ContextWithSpanContext(j.ctx, span.SpanContext()), span
+}
+
+func (j *job) setSpanAttributes(span Span) {
+	span.SetAttribute("job.id", j.ID)
+	span.SetAttribute("job.description", j.Description)
+	if j.Type != "" {
+		span.SetAttribute("job.type", j.Type)
+	}
+}
@@ -29,15 +29,49 @@ type PurgeJobsHandler struct {
 	started  bool
 	dChan    chan struct{}
 	mu       sync.Mutex
+	opt      options
+	archiver *Archiver
 }
 
-func NewPurgeJobsHandler(jobHdl JobHandler, interval, maxAge time.Duration) *PurgeJobsHandler {
-	return &PurgeJobsHandler{
+type purgeOptions struct {
+	logger   Log
+	archiver *Archiver
+}
+
+type PurgeOption func(*purgeOptions)
+
+// WithPurgeLogger sets the logger of a PurgeJobsHandler, defaults to the package level Logger.
+func WithPurgeLogger(l Log) PurgeOption {
+	return func(o *purgeOptions) {
+		o.logger = l
+	}
+}
+
+// WithArchiver sets an archiver receiving the jobs purged by a PurgeJobsHandler. Jobs are only
+// archived if the job handler implements Purger.
+func WithArchiver(a *Archiver) PurgeOption {
+	return func(o *purgeOptions) {
+		o.archiver = a
+	}
+}
+
+func NewPurgeJobsHandler(jobHdl JobHandler, interval, maxAge time.Duration, opts ...PurgeOption) *PurgeJobsHandler {
+	var o purgeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	h := &PurgeJobsHandler{
 		jobHdl:   jobHdl,
 		interval: interval,
 		maxAge:   maxAge,
 		dChan:    make(chan struct{}),
+		opt:      options{logger: o.logger},
+		archiver: o.archiver,
 	}
+	if _, ok := jobHdl.(Purger); h.archiver != nil && !ok {
+		h.opt.warningf("job handler does not implement Purger, purged jobs are not archived")
+	}
+	return h
 }
 
 func (h *PurgeJobsHandler) Start(ctx context.Context) {
@@ -55,24 +89,32 @@ func (h *PurgeJobsHandler) Wait() {
 	<-h.dChan
 }
 
+func (h *PurgeJobsHandler) purge(ctx context.Context) {
+	h.opt.debugf("purging old jobs ...")
+	purger, ok := h.jobHdl.(Purger)
+	if h.archiver == nil || !ok {
+		if n, err := h.jobHdl.PurgeJobs(ctx, h.maxAge); err != nil {
+			h.opt.errorf("purging old jobs failed: %s", err)
+		} else {
+			h.opt.debugf("purged '%d' old jobs", n)
+		}
+		return
+	}
+	jobs, err := purger.Purge(ctx, h.maxAge, h.archiver.Append)
+	if err != nil {
+		h.opt.errorf("purging and archiving old jobs failed: %s", err)
+		return
+	}
+	h.opt.debugf("purged and archived '%d' old jobs", len(jobs))
+}
+
 func (h *PurgeJobsHandler) run(ctx context.Context) {
 	timer := time.NewTimer(h.interval)
 	loop := true
 	for loop {
 		select {
 		case <-timer.C:
-			if Logger != nil {
-				Logger.Debugf("purging old jobs ...")
-			}
-			if n, err := h.jobHdl.PurgeJobs(ctx, h.maxAge); err != nil {
-				if Logger != nil {
-					Logger.Errorf("purging old jobs failed: %s", err)
-				}
-			} else {
-				if Logger != nil {
-					Logger.Debugf("purged '%d' old jobs", n)
-				}
-			}
+			h.purge(ctx)
 			timer.Reset(h.interval)
 		case <-ctx.Done():
 			loop = false
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Job-Signature"
+	JobIDHeader     = "X-Job-ID"
+)
+
+// Signature returns the value of the SignatureHeader for a payload: "sha256=" followed by the hex encoded HMAC-SHA256.
+func Signature(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliver runs the callbacks and sends the webhooks until delivered or the context is done.
+func (o *options) deliver(ctx context.Context, job lib.Job, callbacks []func(lib.Job), webhooks []string) {
+	for _, f := range callbacks {
+		f(job)
+	}
+	for _, url := range webhooks {
+		go o.sendWebhook(ctx, url, job)
+	}
+}
+
+func (o *options) sendWebhook(ctx context.Context, url string, job lib.Job) {
+	payload, err := json.Marshal(job)
+	if err != nil {
+		o.errorf("job '%s' webhook: marshaling failed: %s", job.ID, err)
+		return
+	}
+	delay := o.webhookDelay
+	for i := 0; i <= o.webhookRetries; i++ {
+		if i > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				o.warningf("job '%s' webhook delivery to '%s' aborted: %s", job.ID, url, ctx.Err())
+				return
+			}
+			delay *= 2
+		}
+		if err = o.postWebhook(ctx, url, job.ID, payload); err == nil {
+			o.debugf("job '%s' webhook delivered", job.ID)
+			return
+		}
+		o.warningf("job '%s' webhook attempt %d failed: %s", job.ID, i+1, err)
+	}
+	o.errorf("job '%s' webhook delivery to '%s' failed", job.ID, url)
+}
+
+func (o *options) postWebhook(ctx context.Context, url, jID string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(JobIDHeader, jID)
+	if len(o.webhookSecret) > 0 {
+		req.Header.Set(SignatureHeader, Signature(o.webhookSecret, payload))
+	}
+	resp, err := o.webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%d %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+	}
+	return nil
+}
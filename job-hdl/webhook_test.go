@@ -0,0 +1,138 @@
+/*
+ * Copyright 2026 InfAI (CC SES)
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *    http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job_hdl_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	job_hdl "github.com/SENERGY-Platform/mgw-go-service-base/job-hdl"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/jhtest"
+	"github.com/SENERGY-Platform/mgw-go-service-base/job-hdl/lib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWebhook(t *testing.T) {
+	secret := []byte("secret")
+	var mu sync.Mutex
+	attempts := 0
+	received := make(chan lib.Job, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		n := attempts
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		payload, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		if sig := r.Header.Get(job_hdl.SignatureHeader); sig != job_hdl.Signature(secret, payload) {
+			t.Errorf("invalid signature '%s'", sig)
+		}
+		var j lib.Job
+		if err = json.Unmarshal(payload, &j); err != nil {
+			t.Error(err)
+			return
+		}
+		if r.Header.Get(job_hdl.JobIDHeader) != j.ID {
+			t.Errorf("job id header '%s' does not match '%s'", r.Header.Get(job_hdl.JobIDHeader), j.ID)
+		}
+		received <- j
+	}))
+	defer srv.Close()
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(context.Background(), exec, job_hdl.WithWebhookSecret(secret), job_hdl.WithWebhookRetries(2, 10*time.Millisecond))
+	var cbkJob lib.Job
+	id, err := hdl.CreateWith(context.Background(), "test", jhtest.Result("done", nil), job_hdl.WithWebhook(srv.URL), job_hdl.WithCallback(func(j lib.Job) {
+		cbkJob = j
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	jhtest.AssertResult(t, cbkJob, "done")
+	select {
+	case j := <-received:
+		if j.ID != id {
+			t.Errorf("webhook job id is '%s', expected '%s'", j.ID, id)
+		}
+		jhtest.AssertResult(t, j, "done")
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not received")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("webhook attempts '%d', expected '2'", attempts)
+	}
+}
+
+type chanLogger struct {
+	warnings chan string
+}
+
+func (l *chanLogger) Errorf(string, ...any) {}
+
+func (l *chanLogger) Warningf(format string, arg ...any) {
+	l.warnings <- fmt.Sprintf(format, arg...)
+}
+
+func (l *chanLogger) Debugf(string, ...any) {}
+
+func TestWebhookShutdown(t *testing.T) {
+	attempts := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts <- struct{}{}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	logger := &chanLogger{warnings: make(chan string, 10)}
+	ctx, cf := context.WithCancel(context.Background())
+	exec := jhtest.NewExecutor()
+	hdl := job_hdl.New(ctx, exec, job_hdl.WithLogger(logger), job_hdl.WithWebhookRetries(3, time.Hour))
+	if _, err := hdl.CreateWith(context.Background(), "test", jhtest.Result(nil, nil), job_hdl.WithWebhook(srv.URL)); err != nil {
+		t.Fatal(err)
+	}
+	exec.RunAll()
+	<-attempts
+	cf()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case msg := <-logger.warnings:
+			if strings.Contains(msg, "aborted") {
+				if len(attempts) != 0 {
+					t.Errorf("'%d' further attempts after shutdown", len(attempts))
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("webhook delivery not aborted on shutdown")
+		}
+	}
+}